@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// AES flags.
+var (
+	// aesKey is the key used by the aes mode: 64 hexadecimal digits for a
+	// 256-bit key, or otherwise a passphrase whose SHA-256 digest is the key.
+	aesKey = ""
+	// aesNonce, if set, is the nonce used by the aes mode in hexadecimal.
+	// It exists to produce deterministic output for testing and must never
+	// be reused for different inputs.
+	aesNonce = ""
+)
+
+// aesKeyBytes returns the 256-bit key given by -key and whether it was derived
+// from a passphrase.
+func aesKeyBytes() (key []byte, derived bool) {
+	if aesKey == "" {
+		fatalf("aes mode requires a -key")
+	}
+	if len(aesKey) == 64 {
+		if key, err := hex.DecodeString(aesKey); err == nil {
+			return key, false
+		}
+	}
+	sum := sha256.Sum256([]byte(aesKey))
+	return sum[:], true
+}
+
+// writeAES writes b encrypted with AES-256-GCM as a []byte containing the
+// nonce followed by the ciphertext. The key is never written; it must be
+// supplied to the decryptAES function (written after the output) at runtime.
+func writeAES(buf *bytes.Buffer, b []byte, depth int) {
+	key, _ := aesKeyBytes()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		fatalf("unable to encrypt: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		fatalf("unable to encrypt: %v", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if aesNonce != "" {
+		nonce, err = hex.DecodeString(aesNonce)
+		if err != nil || len(nonce) != gcm.NonceSize() {
+			fatalf("invalid -nonce %q: must be %d bytes in hexadecimal", aesNonce, gcm.NonceSize())
+		}
+	} else if _, err := rand.Read(nonce); err != nil {
+		fatalf("unable to generate nonce: %v", err)
+	}
+
+	write(buf, gcm.Seal(nonce, nonce, b, nil), "0b", depth)
+}
+
+// writeDecryptAES writes a decryptAES function for decrypting the output of
+// the aes mode.
+func writeDecryptAES(buf *bytes.Buffer, depth int) {
+	src := "// decryptAES decrypts data, a nonce followed by AES-256-GCM ciphertext, with\n" +
+		"// key. The key is not embedded in the program and must be supplied at runtime\n" +
+		"// (e.g., from the environment or a configuration file).\n"
+	if _, derived := aesKeyBytes(); derived {
+		src += "//\n" +
+			"// The key is the SHA-256 digest of a passphrase:\n" +
+			"//\n" +
+			"//\tkey := sha256.Sum256([]byte(passphrase))\n" +
+			"//\tplaintext, err := decryptAES(key[:], data)\n"
+	}
+	writeSource(buf, src+decryptAESFunc, depth)
+}
+
+// decryptAESFunc is the source of the function written by the aes mode.
+const decryptAESFunc = `func decryptAES(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	n := gcm.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("decryptAES: data is too short")
+	}
+	return gcm.Open(nil, data[:n], data[n:], nil)
+}`
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// decryptAES is the function written by the aes mode. TestDecryptAESFunc checks
+// that it is a copy of decryptAESFunc.
+func decryptAES(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	n := gcm.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("decryptAES: data is too short")
+	}
+	return gcm.Open(nil, data[:n], data[n:], nil)
+}
+
+func TestDecryptAESFunc(t *testing.T) {
+	src, err := ioutil.ReadFile("aes_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(src, []byte(decryptAESFunc)) {
+		t.Error("decryptAES in aes_test.go differs from decryptAESFunc")
+	}
+}
+
+// parseBytes parses a []byte literal of hexadecimal bytes, as written by the b
+// and 0b modes.
+func parseBytes(t *testing.T, lit string) []byte {
+	t.Helper()
+	if !strings.HasPrefix(lit, "[]byte{") || !strings.HasSuffix(lit, "}") {
+		t.Fatalf("%s is not a []byte literal", lit)
+	}
+	var p []byte
+	for _, f := range strings.FieldsFunc(lit[len("[]byte{"):len(lit)-1], func(r rune) bool { return r == ',' || r == ' ' }) {
+		c, err := strconv.ParseUint(f, 0, 8)
+		if err != nil {
+			t.Fatalf("%s: invalid byte %q", lit, f)
+		}
+		p = append(p, byte(c))
+	}
+	return p
+}
+
+func TestAESRoundTrip(t *testing.T) {
+	defer func(key, nonce string) { aesKey, aesNonce = key, nonce }(aesKey, aesNonce)
+	aesKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	aesNonce = "000102030405060708090a0b"
+
+	const (
+		in = "attack at dawn"
+		// want is the nonce followed by the ciphertext and tag.
+		want = "000102030405060708090a0b" + "2676a27aa68ee27af961f3eac687" + "4a645e8e20a54e0ea3a3e272247f1284"
+	)
+	data := parseBytes(t, writeString([]byte(in), "aes"))
+	if got := hex.EncodeToString(data); got != want {
+		t.Errorf("aes mode of %q = %s; want %s", in, got, want)
+	}
+
+	key, _ := hex.DecodeString(aesKey)
+	got, err := decryptAES(key, data)
+	if err != nil {
+		t.Fatalf("decryptAES() error = %v", err)
+	}
+	if string(got) != in {
+		t.Errorf("decryptAES() = %q; want %q", got, in)
+	}
+
+	data[len(data)-1] ^= 1
+	if _, err := decryptAES(key, data); err == nil {
+		t.Error("decryptAES() of modified data succeeded; want an authentication error")
+	}
+}
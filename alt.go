@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// longestFirst controls whether the alternatives of the alt mode are ordered
+// from longest to shortest.
+var longestFirst = false
+
+// writeAlt writes the non-empty lines of b as a regular expression matching any
+// of them, (?:line1|line2|...), with each line escaped by regexp.QuoteMeta. The
+// expression is written as a backquoted string if possible. Since the regexp
+// package prefers the leftmost alternative that matches, -longest-first orders
+// longer lines first so that they match in preference to their prefixes. Input
+// of no non-empty lines is an error, since (?:) would match everything.
+func writeAlt(buf *bytes.Buffer, b []byte, depth int) {
+	var alts []string
+	for _, line := range splitLines(b) {
+		if line != "" {
+			alts = append(alts, line)
+		}
+	}
+	if len(alts) == 0 {
+		fatalf("alt mode requires at least one non-empty line")
+	}
+	if longestFirst {
+		sort.SliceStable(alts, func(i, j int) bool { return len(alts[i]) > len(alts[j]) })
+	}
+	for i, alt := range alts {
+		alts[i] = regexp.QuoteMeta(alt)
+	}
+	write(buf, []byte("(?:"+strings.Join(alts, "|")+")"), "r", depth)
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestWriteAlt(t *testing.T) {
+	defer func(prev bool) { longestFirst = prev }(longestFirst)
+
+	cases := []struct {
+		in      string
+		longest bool
+		want    string
+	}{
+		{"a\nab\n\na.b\n", false, "`(?:a|ab|a\\.b)`"},
+		{"a\nab\n\na.b\n", true, "`(?:a\\.b|ab|a)`"},
+		{"`\n", false, "\"(?:`)\""},
+	}
+	for _, c := range cases {
+		longestFirst = c.longest
+		if got := writeString([]byte(c.in), "alt"); got != c.want {
+			t.Errorf("alt of %q (-longest-first=%t) = %s; want %s", c.in, c.longest, got, c.want)
+		}
+	}
+
+	for _, in := range []string{"", "\n", "\n\n\n"} {
+		if msg := catchFatal(func() { writeString([]byte(in), "alt") }); msg == "" {
+			t.Errorf("alt of %q succeeded; want error", in)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// commentStyle is the style of the comments written by annotations (-caret,
+// -with-text, and -note-size): "block", "line", or "none". If empty, each
+// annotation uses its default style.
+var commentStyle = ""
+
+// noteSize controls whether expressions are annotated with the size of their
+// input in bytes.
+var noteSize = false
+
+// annotationStyle returns the comment style of an annotation whose default
+// style is def.
+func annotationStyle(def string) string {
+	if commentStyle == "" {
+		return def
+	}
+	return commentStyle
+}
+
+// blockComment returns text as a block comment preceded by a space. Any */ in
+// text is written as *\x2f so that it does not end the comment.
+func blockComment(text string) string {
+	return " /* " + strings.Replace(text, "*/", `*\x2f`, -1) + " */"
+}
+
+// lineComment returns text as a line comment preceded by a space.
+func lineComment(text string) string {
+	return " // " + text
+}
+
+// writeAnnotations writes the annotations of b, written in the given mode, that
+// follow its expression. Block comments are written to buf. If the expression
+// is annotated with line comments, their text is returned instead, joined by
+// semicolons, and the caller must write it before the next line break.
+func writeAnnotations(buf *bytes.Buffer, b []byte, mode string) (comment string) {
+	var texts []string
+	auto := ""
+	if mode == "auto" {
+		auto = autoMode(b)
+		mode = auto
+	}
+	if withText && isByteMode(mode) {
+		texts = append(texts, strconv.Quote(string(b)))
+	}
+	if mode == "sorted" {
+		texts = append(texts, "sorted")
+	}
+	if mode == "cstrbytes" {
+		texts = append(texts, "C string: "+strconv.Quote(string(bytes.TrimSuffix(cString(b), []byte{0}))))
+	}
+	if mode == "rot13" {
+		texts = append(texts, caesarNote())
+	}
+	if auto != "" {
+		texts = append(texts, "auto: "+auto)
+	}
+	if noteSize && exprType(mode, b) != "" {
+		if len(b) == 1 {
+			texts = append(texts, "1 byte")
+		} else {
+			texts = append(texts, strconv.Itoa(len(b))+" bytes")
+		}
+	}
+
+	switch annotationStyle("line") {
+	case "block":
+		for _, text := range texts {
+			buf.WriteString(blockComment(text))
+		}
+	case "line":
+		return strings.Join(texts, "; ")
+	}
+	return ""
+}
+
+// writeSep writes sep to buf following an expression annotated with the line
+// comment, if not empty. Since nothing may follow a line comment on its line,
+// comment is written before the first newline in sep or, if sep does not
+// contain a newline, is followed by one.
+func writeSep(buf *bytes.Buffer, sep, comment string) {
+	if comment == "" {
+		buf.WriteString(sep)
+		return
+	}
+	if i := strings.IndexByte(sep, '\n'); i != -1 {
+		buf.WriteString(strings.TrimRight(sep[:i], " \t") + lineComment(comment) + sep[i:])
+		return
+	}
+	buf.WriteString(strings.TrimRight(sep, " \t") + lineComment(comment) + "\n")
+}
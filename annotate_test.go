@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestWithText(t *testing.T) {
+	defer func(prev bool) { withText = prev }(withText)
+	withText = true
+
+	cases := []struct {
+		mode, in, want string
+	}{
+		{"b", "st", `[]byte{0x73, 0x74} // "st"`},
+		{"b", "\x00\xff\n", `[]byte{0x0, 0xff, 0xa} // "\x00\xff\n"`},
+		{"x", "\x1b[0m", `"\x1b\x5b\x30\x6d" // "\x1b[0m"`},
+		// String modes are readable already, so they are not annotated.
+		{"q", "st", `"st"`},
+	}
+	for _, c := range cases {
+		if got := renderString(c.mode, c.in); got != c.want {
+			t.Errorf("%s mode with -with-text of %q = %s; want %s", c.mode, c.in, got, c.want)
+		}
+	}
+}
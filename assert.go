@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// assertLen, if not negative, is the length of the input checked at compile
+// time by the declaration written with -var.
+var assertLen = -1
+
+// writeAssertLen writes b in the given byte mode as a variable, named by -var,
+// followed by constants that overflow, failing to compile, if its length is
+// not -assert-len. The length of a slice is never constant, so a slice is
+// declared by slicing an array variable (named by -var with an Array suffix)
+// whose length is checked instead. Arrays are written as [...]byte rather than
+// [N]byte, since an array literal shorter than its type is padded with zeroes.
+func writeAssertLen(buf *bytes.Buffer, b []byte, mode string, depth int) {
+	array, inner := varName, "b"
+	switch mode {
+	case "b", "0b":
+		array = varName + "Array"
+	case "ba", "0ba":
+	default:
+		fatalf("-assert-len is not supported by mode %q", mode)
+	}
+	if mode[0] == '0' {
+		inner = "0b"
+	}
+	if len(b) != assertLen {
+		fatalf("-assert-len %d: input is %d bytes", assertLen, len(b))
+	}
+
+	var lit bytes.Buffer
+	write(&lit, b, inner, depth)
+	buf.WriteString("var " + array + " = [...]" + strings.TrimPrefix(lit.String(), "[]"))
+	if comment := writeAnnotations(buf, b, mode); comment != "" {
+		buf.WriteString(lineComment(comment))
+	}
+	if array != varName {
+		newline(buf, depth)
+		buf.WriteString("var " + varName + " = " + array + "[:]")
+	}
+
+	n := strconv.Itoa(assertLen)
+	writeSource(buf, "\n\n"+
+		"// The length of "+array+" is checked at compile time: one of these\n"+
+		"// constants overflows if it is not "+n+".\n"+
+		"const (\n"+
+		"\t_ = uint(len("+array+") - "+n+")\n"+
+		"\t_ = uint("+n+" - len("+array+"))\n"+
+		")", depth)
+}
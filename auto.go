@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// autoModes is the comma-separated list of modes from which the auto mode
+// chooses.
+var autoModes = "bs,bsa,b"
+
+// autoCandidates are the modes allowed in -auto-modes: those that write any
+// input as a single expression without further flags.
+var autoCandidates = map[string]bool{
+	"q": true, "qa": true, "ql": true, "qla": true, "r": true, "ra": true,
+	"x": true, "j": true, "bs": true, "bsa": true, "b": true, "0b": true,
+	"ba": true, "0ba": true, "b64lines": true,
+}
+
+// autoMode returns the mode of -auto-modes that writes b in the fewest bytes
+// of source, preferring the earliest listed in case of a tie.
+func autoMode(b []byte) string {
+	best, n := "", 0
+	for _, m := range strings.Split(autoModes, ",") {
+		var out bytes.Buffer
+		write(&out, b, m, 0)
+		if best == "" || out.Len() < n {
+			best, n = m, out.Len()
+		}
+	}
+	return best
+}
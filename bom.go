@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// Byte-order marks removed by -strip-bom.
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16BE = []byte{0xfe, 0xff}
+	bomUTF16LE = []byte{0xff, 0xfe}
+)
+
+// stripBOM returns b without a leading byte-order mark. If b begins with a
+// UTF-8 BOM, the BOM is removed. If b begins with a UTF-16 BOM, the BOM is
+// removed and the rest of b is converted from UTF-16 (in the byte order given
+// by the BOM) to UTF-8. Input without a BOM is returned unchanged.
+//
+// UTF-32 BOMs are not recognized. Since a UTF-32LE BOM begins with a UTF-16LE
+// BOM, UTF-32LE input is treated as UTF-16LE.
+func stripBOM(b []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(b, bomUTF8):
+		return b[len(bomUTF8):]
+	case bytes.HasPrefix(b, bomUTF16BE):
+		order = binary.BigEndian
+	case bytes.HasPrefix(b, bomUTF16LE):
+		order = binary.LittleEndian
+	default:
+		return b
+	}
+
+	b = b[2:]
+	if len(b)%2 != 0 {
+		fatalf("unable to strip BOM: UTF-16 input has an odd number of bytes")
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = order.Uint16(b[i*2:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseColor parses a hex color code of 3 (#rgb), 6 (#rrggbb), or 8
+// (#rrggbbaa) digits, with or without a leading #, into its red, green, blue,
+// and alpha channels. Each digit of the 3-digit shorthand is repeated, so #abc
+// is #aabbcc. If the code does not give alpha, it is 0xff.
+func parseColor(s string) (rgba [4]byte, ok bool) {
+	s = strings.TrimPrefix(s, "#")
+	switch len(s) {
+	case 3:
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]}) + "ff"
+	case 6:
+		s += "ff"
+	case 8:
+	default:
+		return rgba, false
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return rgba, false
+	}
+	for i := range rgba {
+		rgba[i] = byte(n >> uint(24-8*i))
+	}
+	return rgba, true
+}
+
+// writeColors writes the hex color codes of b, one per line, as a
+// []color.RGBA. Surrounding whitespace and blank lines are ignored. Malformed
+// codes are reported by line number.
+func writeColors(buf *bytes.Buffer, b []byte, depth int) {
+	var colors [][4]byte
+	for i, line := range splitLines(b) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		rgba, ok := parseColor(line)
+		if !ok {
+			fatalf("color: line %d: invalid color %q: must be #rgb, #rrggbb, or #rrggbbaa", i+1, line)
+		}
+		colors = append(colors, rgba)
+	}
+	if shuffleElems {
+		shuffle(len(colors), func(i, j int) { colors[i], colors[j] = colors[j], colors[i] })
+	}
+
+	buf.WriteString("[]color.RGBA{")
+	if len(colors) == 0 {
+		buf.WriteByte('}')
+		return
+	}
+	for _, c := range colors {
+		newline(buf, depth+1)
+		fmt.Fprintf(buf, "{0x%02x, 0x%02x, 0x%02x, 0x%02x},", c[0], c[1], c[2], c[3])
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
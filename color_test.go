@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	cases := []struct {
+		in   string
+		want [4]byte
+		ok   bool
+	}{
+		{"#abc", [4]byte{0xaa, 0xbb, 0xcc, 0xff}, true},
+		{"abc", [4]byte{0xaa, 0xbb, 0xcc, 0xff}, true},
+		{"#ABC", [4]byte{0xaa, 0xbb, 0xcc, 0xff}, true},
+		{"#123456", [4]byte{0x12, 0x34, 0x56, 0xff}, true},
+		{"#12345678", [4]byte{0x12, 0x34, 0x56, 0x78}, true},
+		{"00000000", [4]byte{0, 0, 0, 0}, true},
+		{"#ab", [4]byte{}, false},
+		{"#abcd", [4]byte{}, false},
+		{"#12g", [4]byte{}, false},
+		{"#+12345", [4]byte{}, false},
+		{"##abc", [4]byte{}, false},
+	}
+	for _, c := range cases {
+		got, ok := parseColor(c.in)
+		if ok != c.ok || got != c.want {
+			t.Errorf("parseColor(%q) = %#v, %v; want %#v, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestWriteColors(t *testing.T) {
+	const want = "[]color.RGBA{\n" +
+		"\t{0xaa, 0xbb, 0xcc, 0xff},\n" +
+		"\t{0x12, 0x34, 0x56, 0x78},\n" +
+		"}"
+	if got := writeString([]byte("#abc\n\n  12345678 \r\n"), "color"); got != want {
+		t.Errorf("color mode =\n%s\nwant\n%s", got, want)
+	}
+
+	msg := catchFatal(func() { writeString([]byte("#abc\n#xyz\n"), "color") })
+	if !strings.Contains(msg, "line 2") {
+		t.Errorf("color mode error = %q; want it to report line 2", msg)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "bytes"
+
+// forceNUL controls whether the cstrbytes mode appends a NUL to input that
+// already ends with one.
+var forceNUL = false
+
+// cString returns b terminated by a NUL byte. If b already ends with a NUL, it
+// is returned unchanged unless -force-nul is set.
+func cString(b []byte) []byte {
+	if len(b) > 0 && b[len(b)-1] == 0 && !forceNUL {
+		return b
+	}
+	return append(b[:len(b):len(b)], 0)
+}
+
+// writeCString writes b, terminated by a NUL byte, as a byte slice of octets
+// for passing to C functions that expect a NUL-terminated string.
+func writeCString(buf *bytes.Buffer, b []byte, depth int) {
+	write(buf, cString(b), "0b", depth)
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestCString(t *testing.T) {
+	defer func(prev bool) { forceNUL = prev }(forceNUL)
+
+	cases := []struct {
+		in    string
+		force bool
+		want  string
+	}{
+		{"", false, "\x00"},
+		{"hi", false, "hi\x00"},
+		{"hi\x00", false, "hi\x00"},
+		{"hi\x00", true, "hi\x00\x00"},
+		{"a\x00b", false, "a\x00b\x00"},
+	}
+	for _, c := range cases {
+		forceNUL = c.force
+		in := []byte(c.in)
+		if got := cString(in); string(got) != c.want {
+			t.Errorf("cString(%q) with -force-nul=%v = %q; want %q", c.in, c.force, got, c.want)
+		}
+		if string(in) != c.in {
+			t.Errorf("cString(%q) modified its input to %q", c.in, in)
+		}
+	}
+}
+
+func TestCStrBytes(t *testing.T) {
+	const want = `[]byte{0x68, 0x69, 0x00} // C string: "hi"`
+	if got := renderString("cstrbytes", "hi\x00"); got != want {
+		t.Errorf("cstrbytes mode = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// noHeader controls whether the first row of csv input is treated as data
+// rather than field names.
+var noHeader = false
+
+// exportedName returns s converted to an exported Go identifier by removing
+// any characters that are not letters or digits and capitalizing the first
+// letter of each word. If s contains no letters or digits, it returns an empty
+// string.
+func exportedName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	name := strings.Join(words, "")
+	if name != "" && !unicode.IsUpper([]rune(name)[0]) {
+		name = "F" + name
+	}
+	return name
+}
+
+// fieldNames returns a unique exported field name for each of header. Empty
+// names are replaced with F0, F1, and so on, by column, and duplicate names
+// are suffixed with their column.
+func fieldNames(header []string) []string {
+	names := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, h := range header {
+		name := exportedName(h)
+		if name == "" {
+			name = "F" + strconv.Itoa(i)
+		}
+		for seen[name] {
+			name += strconv.Itoa(i)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// writeStructSlice writes a slice of anonymous structs whose fields are given
+// by names and types. Each row holds one Go expression per field.
+func writeStructSlice(buf *bytes.Buffer, names, types []string, rows [][]string, depth int) {
+	width := 0
+	for _, name := range names {
+		if n := utf8.RuneCountInString(name); n > width {
+			width = n
+		}
+	}
+
+	buf.WriteString("[]struct {")
+	for i, name := range names {
+		newline(buf, depth+1)
+		buf.WriteString(name + strings.Repeat(" ", width-utf8.RuneCountInString(name)+1) + types[i])
+	}
+	newline(buf, depth)
+	buf.WriteString("}{")
+	if len(rows) == 0 {
+		buf.WriteByte('}')
+		return
+	}
+	for _, row := range rows {
+		newline(buf, depth+1)
+		buf.WriteString("{" + strings.Join(row, ", ") + "},")
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
+
+// writeCSV writes the CSV records of b as a slice of anonymous structs with a
+// string field for each column. Field names are taken from the first record
+// unless -no-header is set, in which case they are F0, F1, and so on.
+func writeCSV(buf *bytes.Buffer, b []byte, depth int) {
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		fatalf("unable to parse CSV: %v", err)
+	}
+	if len(records) == 0 {
+		fatalf("unable to parse CSV: input is empty")
+	}
+
+	var names []string
+	if noHeader {
+		names = fieldNames(make([]string, len(records[0])))
+	} else {
+		names, records = fieldNames(records[0]), records[1:]
+	}
+
+	types := make([]string, len(names))
+	for i := range types {
+		types[i] = "string"
+	}
+
+	for _, rec := range records {
+		for i, v := range rec {
+			rec[i] = strconv.Quote(v)
+		}
+	}
+	if shuffleElems {
+		shuffle(len(records), func(i, j int) { records[i], records[j] = records[j], records[i] })
+	}
+	writeStructSlice(buf, names, types, records, depth)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestExportedName(t *testing.T) {
+	cases := []struct {
+		s, want string
+	}{
+		{"name", "Name"},
+		{"first_name", "FirstName"},
+		{"éclair", "Éclair"},
+		{"été", "Été"},
+		{"1st", "F1st"},
+		{"名前", "F名前"},
+		{"--", ""},
+	}
+	for _, c := range cases {
+		got := exportedName(c.s)
+		if got != c.want {
+			t.Errorf("exportedName(%q) = %q; want %q", c.s, got, c.want)
+		}
+		if got != "" && !isIdentifier(got) {
+			t.Errorf("exportedName(%q) = %q: not an identifier", c.s, got)
+		}
+	}
+}
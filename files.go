@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// stringList is a flag.Value holding each value of a flag that may be
+// repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// File flags.
+var (
+	// inputFiles are the files whose contents are read as inputs in place
+	// of ARGS or standard input.
+	inputFiles stringList
+	// mapVar, if set, is the name of a map variable declared with the
+	// contents of each of inputFiles, keyed by file name.
+	mapVar = ""
+	// fullPath controls whether the keys of -mapvar are the paths given to
+	// -f instead of their base names.
+	fullPath = false
+	// pkgName, if set, is the name of the package declared before the
+	// output.
+	pkgName = ""
+	// genHeader controls whether the output begins with a comment marking
+	// it as generated code.
+	genHeader = false
+)
+
+// writeFileHeader writes the generated code comment of -header and package
+// clause of -package, if set, each followed by a blank line.
+func writeFileHeader(buf *bytes.Buffer) {
+	if genHeader {
+		buf.WriteString("// Code generated by goquote. DO NOT EDIT.\n\n")
+	}
+	if pkgName != "" {
+		buf.WriteString("package " + pkgName + "\n\n")
+	}
+}
+
+// writeMapVar writes a map variable, named by -mapvar, of the contents of each
+// -f file, given by inputs, keyed by the file's base name or, with -fullpath,
+// its path. Each file is written in the given mode, which must write a
+// []byte, or as a byte slice of octets if mode is empty. The type of byte
+// slice literals is elided, as allowed for map values.
+func writeMapVar(buf *bytes.Buffer, inputs [][]byte, mode string) {
+	if mode == "" {
+		mode = "b"
+	}
+
+	writeNolint(buf, 0)
+	buf.WriteString("var " + mapVar + " = map[string][]byte{")
+	seen := make(map[string]bool, len(inputs))
+	for i, b := range inputs {
+		if exprType(mode, b) != "[]byte" {
+			fatalf("-mapvar is not supported by mode %q", mode)
+		}
+		key := filepath.ToSlash(inputFiles[i])
+		if !fullPath {
+			key = filepath.Base(inputFiles[i])
+		}
+		if seen[key] {
+			fatalf("-mapvar: more than one file is named %q (see -fullpath)", key)
+		}
+		seen[key] = true
+
+		var v bytes.Buffer
+		comment := writeExpr(&v, b, mode, 1)
+		newline(buf, 1)
+		buf.WriteString(strconv.Quote(key) + ": ")
+		if p := v.Bytes(); bytes.HasPrefix(p, []byte("[]byte{")) {
+			v.Next(len("[]byte"))
+		}
+		v.WriteTo(buf)
+		buf.WriteByte(',')
+		if comment != "" {
+			buf.WriteString(lineComment(comment))
+		}
+	}
+	newline(buf, 0)
+	buf.WriteByte('}')
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Flag definition flags, used by the flagdef mode.
+var (
+	// flagName is the name of the flag defined by flagdef.
+	flagName = ""
+	// flagUsage is the usage text of the flag defined by flagdef.
+	flagUsage = ""
+)
+
+// flagDef returns the flag package function defining a flag whose default
+// value is b with surrounding whitespace removed, and the default as a Go
+// expression. The type of the flag is inferred from the default: an integer
+// defines an Int flag (or Int64, if it does not fit in 32 bits), a boolean as
+// accepted by strconv.ParseBool a Bool flag, and anything else a String flag.
+func flagDef(b []byte) (fn, value string) {
+	s := strings.TrimSpace(string(b))
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n != int64(int32(n)) {
+			return "Int64", strconv.FormatInt(n, 10)
+		}
+		return "Int", strconv.FormatInt(n, 10)
+	}
+	if t, err := strconv.ParseBool(s); err == nil {
+		return "Bool", strconv.FormatBool(t)
+	}
+	return "String", strconv.Quote(s)
+}
+
+// writeFlagDef writes a call of the flag package function defining the flag
+// named by -flag-name, with b as its default value and -flag-usage as its
+// usage text.
+func writeFlagDef(buf *bytes.Buffer, b []byte) {
+	if flagName == "" {
+		fatalf("flagdef mode requires a -flag-name")
+	}
+	fn, value := flagDef(b)
+	buf.WriteString("flag." + fn + "(" + strconv.Quote(flagName) + ", " + value + ", " + strconv.Quote(flagUsage) + ")")
+}
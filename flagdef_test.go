@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestFlagDef(t *testing.T) {
+	cases := []struct {
+		in, fn, value string
+	}{
+		{"8080", "Int", "8080"},
+		{" -1\n", "Int", "-1"},
+		{"2147483648", "Int64", "2147483648"},
+		{"true\n", "Bool", "true"},
+		{"F", "Bool", "false"},
+		{"localhost:8080\n", "String", `"localhost:8080"`},
+		{"  two words \n", "String", `"two words"`},
+		{"", "String", `""`},
+		{"99999999999999999999", "String", `"99999999999999999999"`},
+	}
+	for _, c := range cases {
+		if fn, value := flagDef([]byte(c.in)); fn != c.fn || value != c.value {
+			t.Errorf("flagDef(%q) = %s, %s; want %s, %s", c.in, fn, value, c.fn, c.value)
+		}
+	}
+}
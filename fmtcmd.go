@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// fmtCmd, if set, is a command, split into fields on whitespace, that the
+// output is piped through before it is written, such as gofumpt or goimports.
+var fmtCmd = ""
+
+// runFmtCmd returns p as formatted by -fmt-cmd: the standard output of the
+// command given p as its standard input. The command's standard error is
+// passed through. If the command fails, a warning is logged and p is returned
+// unchanged.
+func runFmtCmd(p []byte) []byte {
+	args := strings.Fields(fmtCmd)
+	var out bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(p)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Printf("Unable to format output with %q, writing it unformatted: %v", fmtCmd, err)
+		return p
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// fnvBits is the size, 32 or 64, of the FNV-1a hashes written by the fnv mode.
+var fnvBits = 32
+
+// fnvType returns the unsigned integer type of the hashes written by the fnv
+// mode.
+func fnvType() string {
+	return "uint" + strconv.Itoa(fnvBits)
+}
+
+// fnvHash returns the FNV-1a hash of b, as computed by fnv.New32a or
+// fnv.New64a, written as a hexadecimal literal of the full width of the hash.
+func fnvHash(b []byte) string {
+	var sum uint64
+	if fnvBits == 64 {
+		h := fnv.New64a()
+		h.Write(b)
+		sum = h.Sum64()
+	} else {
+		h := fnv.New32a()
+		h.Write(b)
+		sum = uint64(h.Sum32())
+	}
+	h := strconv.FormatUint(sum, 16)
+	return "0x" + strings.Repeat("0", fnvBits/4-len(h)) + h
+}
+
+// writeFNV writes the FNV-1a hash of b converted to its type, so that 64-bit
+// hashes are not untyped constants overflowing int. If there are multiple
+// inputs, elems is instead written as a map of each input's hash to the input.
+// Repeated inputs are written once, and inputs with the same hash are an
+// error, since they cannot be told apart by it.
+func writeFNV(buf *bytes.Buffer, b []byte, depth int) {
+	if elems == nil {
+		buf.WriteString(fnvType() + "(" + fnvHash(b) + ")")
+		return
+	}
+
+	buf.WriteString("map[" + fnvType() + "]string{")
+	seen := make(map[string]string, len(elems))
+	for _, e := range elems {
+		h := fnvHash(e)
+		if prev, ok := seen[h]; ok {
+			if prev == string(e) {
+				continue
+			}
+			fatalf("fnv: %q and %q have the same %d-bit hash, %s", prev, e, fnvBits, h)
+		}
+		seen[h] = string(e)
+		newline(buf, depth+1)
+		buf.WriteString(h + ": " + strconv.Quote(string(e)) + ",")
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Framing flags, applied around the expression written for each input.
+var (
+	// loader, if set, is the name of a function returning the input.
+	loader = ""
+	// loaderString controls whether the loader function returns a string
+	// instead of a []byte.
+	loaderString = false
+	// varName, if set, is the name of a variable declared with the input.
+	varName = ""
+	// constName, if set, is the name of a constant declared with the
+	// input.
+	constName = ""
+	// diffFn controls whether a firstDiff function is written after the
+	// output.
+	diffFn = false
+	// withText controls whether byte modes are followed by a comment
+	// containing the input as a quoted string.
+	withText = false
+	// forceString controls whether string modes are converted to string,
+	// making them typed rather than untyped constants.
+	forceString = false
+	// onceName, if set, is the name of a variable declared with a
+	// sync.OnceValue function returning the input.
+	onceName = ""
+	// docEx controls whether the output is written as a code block in a
+	// Go doc comment.
+	docEx = false
+	// loop controls whether a loop over the -var is written after it.
+	loop = false
+	// nolint, if set, is the comma-separated list of linters ignored by a
+	// //nolint directive on the declaration.
+	nolint = ""
+	// unusedOK controls whether the input is assigned to the blank
+	// identifier, checking that it compiles without declaring a name.
+	unusedOK = false
+	// reflectCheck controls whether an assignment of the -var to a
+	// variable of -type is written after it, checking at compile time that
+	// the types match.
+	reflectCheck = false
+)
+
+// linterList matches a valid -nolint list of golangci-lint linter names.
+var linterList = regexp.MustCompile(`^[a-z][a-z0-9_-]*(,[a-z][a-z0-9_-]*)*$`)
+
+// writeNolint writes the //nolint directive of -nolint, if set, on its own
+// line.
+func writeNolint(buf *bytes.Buffer, depth int) {
+	if nolint != "" {
+		buf.WriteString("//nolint:" + nolint)
+		newline(buf, depth)
+	}
+}
+
+// exprType returns the Go type of the expression written by mode for the input
+// b. Modes that write a call returning a value and an error, such as b64lines,
+// have a type of the form "(T, error)". If mode does not write a single
+// expression of a known type, it returns an empty string.
+func exprType(mode string, b []byte) string {
+	switch mode {
+	case "auto":
+		return exprType(autoMode(b), b)
+	case "b64lines", "hexlines":
+		return "([]byte, error)"
+	case "", "q", "qa", "ql", "qla", "r", "ra", "x", "j", "pem", "alt", "rot13", joinedRawMode:
+		return "string"
+	case "bs", "bsa", "b", "0b", "record", "table", "aes", "cstrbytes", "jumptable":
+		return "[]byte"
+	case "htmltype":
+		return "template." + htmlType()
+	case "gob", "jstruct":
+		return typeName
+	case "flagdef":
+		fn, _ := flagDef(b)
+		return "*" + strings.ToLower(fn)
+	case "words2slice":
+		return "[]string"
+	case "color":
+		return "[]color.RGBA"
+	case "fnv":
+		if elems != nil {
+			return "map[" + fnvType() + "]string"
+		}
+		return fnvType()
+	case "sorted":
+		if elems != nil {
+			return "[]string"
+		}
+		return "[]byte"
+	case "namedargs":
+		return "[]sql.NamedArg"
+	case "ba", "0ba":
+		return "[" + strconv.Itoa(len(b)) + "]byte"
+	}
+	return ""
+}
+
+// isByteMode returns whether mode writes the input as individual bytes, such
+// that it is not readable as text.
+func isByteMode(mode string) bool {
+	switch mode {
+	case "b", "0b", "ba", "0ba", "bs", "bsa", "x":
+		return true
+	}
+	return false
+}
+
+// isIdentifier returns whether s is a valid Go identifier.
+func isIdentifier(s string) bool {
+	if s == "" || s == "_" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// writeSource writes src, a fragment of tab-indented Go source, to buf with
+// each leading tab replaced by -indent and each line after the first indented
+// by depth levels.
+func writeSource(buf *bytes.Buffer, src string, depth int) {
+	for i, line := range strings.Split(src, "\n") {
+		if i > 0 {
+			newline(buf, depth)
+		}
+		trimmed := strings.TrimLeft(line, "\t")
+		buf.WriteString(strings.Repeat(indentUnit, len(line)-len(trimmed)))
+		buf.WriteString(trimmed)
+	}
+}
+
+// valueType returns the type of the value of an expression of type typ, as
+// returned by exprType. If the expression returns a value and an error, the
+// type of the value is returned and decode is true.
+func valueType(typ string) (value string, decode bool) {
+	if strings.HasPrefix(typ, "(") && strings.HasSuffix(typ, ", error)") {
+		return typ[1 : len(typ)-len(", error)")], true
+	}
+	return typ, false
+}
+
+// writeExpr writes b in the given mode, wrapped in any reader selected by
+// -timeout-reader or -err-reader, followed by any annotations of the
+// expression as a whole. If the expression is annotated with a line comment,
+// its text is returned, as with writeAnnotations.
+func writeExpr(buf *bytes.Buffer, b []byte, mode string, depth int) (comment string) {
+	fn := iotestReader()
+	if fn != "" {
+		buf.WriteString(fn + "(" + newReader(mode, b) + "(")
+	}
+	if forceString && exprType(mode, b) == "string" {
+		// Adding "" would leave the constant untyped, so convert it
+		// instead.
+		buf.WriteString("string(")
+		write(buf, b, mode, depth)
+		buf.WriteByte(')')
+	} else {
+		write(buf, b, mode, depth)
+	}
+	if fn != "" {
+		buf.WriteString("))")
+	}
+	return writeAnnotations(buf, b, mode)
+}
+
+// writeFramed writes b in the given mode, framed by any of the framing flags
+// that are set. If the framed output ends with an expression annotated with a
+// line comment, its text is returned, as with writeAnnotations.
+func writeFramed(buf *bytes.Buffer, b []byte, mode string, depth int) (comment string) {
+	if mode == "jstruct" {
+		writeJSONTypes(buf, b, depth)
+		buf.WriteByte('\n')
+		newline(buf, depth)
+	}
+	if loader == "" {
+		writeNolint(buf, depth)
+	}
+
+	switch {
+	case loader != "":
+		writeLoader(buf, b, mode, depth)
+		return ""
+	case onceName != "":
+		writeOnce(buf, b, mode, depth)
+		return ""
+	case assertLen >= 0:
+		writeAssertLen(buf, b, mode, depth)
+		return ""
+	case varName != "":
+		if _, decode := valueType(exprType(mode, b)); decode {
+			// The data is encoded by goquote, so decoding it cannot
+			// fail.
+			buf.WriteString("var " + varName + ", _ = ")
+		} else {
+			buf.WriteString("var " + varName + " = ")
+		}
+	case unusedOK:
+		switch typ, decode := valueType(exprType(mode, b)); {
+		case typ == "":
+			fatalf("-unused-ok is not supported by mode %q", mode)
+		case decode:
+			buf.WriteString("var _, _ = ")
+		default:
+			buf.WriteString("var _ = ")
+		}
+	case constName != "":
+		switch typ := exprType(mode, b); typ {
+		case "string", "uint32", "uint64":
+		default:
+			fatalf("-const is not supported by mode %q", mode)
+		}
+		buf.WriteString("const " + constName + " = ")
+	}
+	return writeExpr(buf, b, mode, depth)
+}
+
+// writeLoader writes a function, named by -loader, that returns b written in
+// the given mode. The function returns a []byte or, with -loader-string, a
+// string, converting the expression as needed. Since a []byte can be modified
+// by its caller, each call of a []byte loader allocates a new copy of its
+// data.
+func writeLoader(buf *bytes.Buffer, b []byte, mode string, depth int) {
+	ret := "[]byte"
+	if loaderString {
+		ret = "string"
+	}
+
+	conv := false
+	switch typ := exprType(mode, b); {
+	case typ == ret:
+	case typ == "string" || typ == "[]byte":
+		conv = true
+	case typ == "":
+		fatalf("-loader is not supported by mode %q", mode)
+	default:
+		fatalf("-loader cannot return a %s as a %s", typ, ret)
+	}
+
+	if ret == "string" {
+		buf.WriteString("// " + loader + " returns its data as a string.")
+	} else {
+		buf.WriteString("// " + loader + " returns a new copy of its data on each call.")
+	}
+	newline(buf, depth)
+	writeNolint(buf, depth)
+	buf.WriteString("func " + loader + "() " + ret + " {")
+	newline(buf, depth+1)
+	buf.WriteString("return ")
+	if conv {
+		buf.WriteString(ret + "(")
+	}
+	write(buf, b, mode, depth+1)
+	if conv {
+		buf.WriteByte(')')
+	}
+	if comment := writeAnnotations(buf, b, mode); comment != "" {
+		buf.WriteString(lineComment(comment))
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
+
+// writeOnce writes a variable, named by -once, holding a sync.OnceValue
+// function that returns b written in the given mode. The expression is only
+// evaluated on the first call of the function. If the mode's expression
+// returns an error, such as the decoding of b64lines, the function panics if
+// the error is not nil.
+func writeOnce(buf *bytes.Buffer, b []byte, mode string, depth int) {
+	ret, decode := valueType(exprType(mode, b))
+	if ret == "" {
+		fatalf("-once is not supported by mode %q", mode)
+	}
+
+	buf.WriteString("var " + onceName + " = sync.OnceValue(func() " + ret + " {")
+	newline(buf, depth+1)
+	if decode {
+		buf.WriteString("v, err := ")
+	} else {
+		buf.WriteString("return ")
+	}
+	if comment := writeExpr(buf, b, mode, depth+1); comment != "" {
+		buf.WriteString(lineComment(comment))
+	}
+	if decode {
+		writeSource(buf, "\nif err != nil {\n\tpanic(err)\n}\nreturn v", depth+1)
+	}
+	newline(buf, depth)
+	buf.WriteString("})")
+}
+
+// writeLoop writes a loop over the bytes of the -var declared with b in the
+// given mode, as a starting point for code processing it. Both the index and
+// the byte are used so that the loop compiles as written.
+func writeLoop(buf *bytes.Buffer, b []byte, mode string, depth int) {
+	if typ, _ := valueType(exprType(mode, b)); !strings.HasSuffix(typ, "]byte") {
+		fatalf("-loop is not supported by mode %q", mode)
+	}
+	writeSource(buf, "for i, b := range "+varName+" {\n\t_, _ = i, b\n}", depth)
+}
+
+// isType returns whether s is a Go type, such as T, pkg.T, or []T.
+func isType(s string) bool {
+	// Parse s as the type of a conversion, since the parser only parses
+	// types on their own as part of an expression.
+	e, err := parser.ParseExpr("(*" + s + ")(nil)")
+	if err != nil {
+		return false
+	}
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	paren, ok := call.Fun.(*ast.ParenExpr)
+	if !ok {
+		return false
+	}
+	star, ok := paren.X.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	return isTypeExpr(star.X)
+}
+
+// isTypeExpr returns whether e is a type expression.
+func isTypeExpr(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.Ident, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.StructType, *ast.InterfaceType:
+		return true
+	case *ast.SelectorExpr:
+		_, ok := e.X.(*ast.Ident)
+		return ok
+	case *ast.StarExpr:
+		return isTypeExpr(e.X)
+	case *ast.ParenExpr:
+		return isTypeExpr(e.X)
+	}
+	return false
+}
+
+// writeTypeCheck writes a declaration assigning the -var to a blank variable of
+// -type, so that compilation fails if the variable's type no longer matches a
+// type declared elsewhere.
+func writeTypeCheck(buf *bytes.Buffer, depth int) {
+	writeSource(buf, "var _ "+typeName+" = "+varName, depth)
+}
+
+// docExample returns p as a code block in a Go doc comment: each line is
+// prefixed with "//" and a tab, except for empty lines, which are written as
+// "//" alone.
+func docExample(p []byte) []byte {
+	var buf bytes.Buffer
+	for i, line := range strings.Split(string(p), "\n") {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString("//")
+		if line != "" {
+			buf.WriteString("\t" + line)
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeFirstDiff writes a firstDiff function for comparing a []byte against
+// another, such as one declared with -var.
+func writeFirstDiff(buf *bytes.Buffer, depth int) {
+	src := "// firstDiff returns the offset of the first byte at which a and b differ, or\n" +
+		"// -1 if they are equal.\n"
+	if varName != "" {
+		src += "//\n" +
+			"//\tif i := firstDiff(got, " + varName + "); i != -1 {\n" +
+			"//\t\tt.Errorf(\"got differs from " + varName + " at offset %d\", i)\n" +
+			"//\t}\n"
+	}
+	writeSource(buf, src+firstDiffFunc, depth)
+}
+
+// firstDiffFunc is the source of the function written by -difffn.
+const firstDiffFunc = `func firstDiff(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) != len(b) {
+		return n
+	}
+	return -1
+}`
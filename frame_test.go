@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVarDecodingModes(t *testing.T) {
+	defer func(prev string) { varName = prev }(varName)
+	varName = "data"
+
+	for _, mode := range []string{"b64lines", "hexlines"} {
+		got := renderString(mode, "hi")
+		if !strings.HasPrefix(got, "var data, _ = ") {
+			t.Errorf("%s mode with -var = %s; want it to assign the value and discard the error", mode, got)
+		}
+	}
+	if got := renderString("b", "hi"); !strings.HasPrefix(got, "var data = ") {
+		t.Errorf("b mode with -var = %s; want a single assignment", got)
+	}
+}
+
+func TestIsType(t *testing.T) {
+	for s, want := range map[string]bool{
+		"T":                  true,
+		"pkg.T":              true,
+		"[]byte":             true,
+		"*T":                 true,
+		"map[string][]pkg.T": true,
+		"[4]byte":            true,
+		"func() error":       true,
+		"struct{ A int }":    true,
+		"interface{}":        true,
+		"chan<- int":         true,
+		"1+2":                false,
+		"f()":                false,
+		"T)(nil)(*U":         false,
+		"a.b.C":              false,
+		"":                   false,
+		"T = 1; var x int":   false,
+		"[]T{}":              false,
+	} {
+		if got := isType(s); got != want {
+			t.Errorf("isType(%q) = %v; want %v", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import "bytes"
+
+// writeGob writes b, an encoding/gob stream, as a call of a function literal
+// that decodes a value of the type named by -type from it. The function panics
+// if decoding fails, since the data is fixed at generation time. The bytes are
+// written as a byte slice of octets so that the stream is embedded exactly.
+func writeGob(buf *bytes.Buffer, b []byte, depth int) {
+	if typeName == "" {
+		fatalf("gob mode requires a -type")
+	}
+
+	buf.WriteString("func() " + typeName + " {")
+	newline(buf, depth+1)
+	buf.WriteString("var v " + typeName)
+	newline(buf, depth+1)
+	buf.WriteString("if err := gob.NewDecoder(bytes.NewReader(")
+	write(buf, b, "0b", depth+1)
+	buf.WriteString(")).Decode(&v); err != nil {")
+	writeSource(buf, "\n\tpanic(err)\n}\nreturn v", depth+1)
+	newline(buf, depth)
+	buf.WriteString("}()")
+}
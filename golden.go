@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// goldenPath, if set, is the path of the golden file written by the
+// updateGolden function written after the -var.
+var goldenPath = ""
+
+// writeGolden writes an updateGolden function that writes the -var declared
+// with b in the given mode to -golden when the test flag -update is set. The
+// update flag is not declared, since a test package declares it only once for
+// all of its golden files.
+func writeGolden(buf *bytes.Buffer, b []byte, mode string, depth int) {
+	data := varName
+	switch typ, _ := valueType(exprType(mode, b)); typ {
+	case "[]byte":
+	case "string":
+		data = "[]byte(" + varName + ")"
+	default:
+		fatalf("-golden is not supported by mode %q", mode)
+	}
+	writeSource(buf, `// updateGolden writes `+varName+` to its golden file if -update is set.
+func updateGolden(t *testing.T) {
+	t.Helper()
+	if !*update {
+		return
+	}
+	if err := os.WriteFile(`+strconv.Quote(goldenPath)+`, `+data+`, 0644); err != nil {
+		t.Fatalf("unable to update golden file: %v", err)
+	}
+}`, depth)
+}
@@ -44,6 +44,7 @@ MODE may be one of the following to change quote behavior:
         []byte("string")
   bsa - Quoted ASCII []byte() slice
         []byte("string")
+        (The string inside bs and bsa may be changed with -bs-inner.)
   b   - Byte slice of octets
         []byte{0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x1}
   0b  - Byte slice of octets (with leading zero)
@@ -54,6 +55,228 @@ MODE may be one of the following to change quote behavior:
         [6]byte{0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x01}
   j   - JSON string
         "string"
+  csv - Slice of structs with a string field per CSV column, named by the
+        header row (or F0, F1, ... with -no-header)
+        []struct {
+        	Name string
+        	Age  string
+        }{
+        	{"Ana", "30"},
+        }
+  tsv - Slice of structs from tab-separated input, whose header row gives
+        each column as NAME[:TYPE]. TYPE is one of string (default), int,
+        bool, or float64, and values are written as literals of their type.
+        []struct {
+        	Name  string
+        	Count int
+        }{
+        	{"Ana", 30},
+        }
+  pem - Backquoted PEM block of type -pem-type
+        `+"`-----BEGIN CERTIFICATE-----\n        c3RyaW5n\n        -----END CERTIFICATE-----\n        `"+`
+  b64lines
+      - Call to base64.StdEncoding.DecodeString with the input's base64
+        encoding as a backquoted string wrapped at -w characters per line,
+        so that line-based diffs only show the lines that changed.
+        Requires importing "encoding/base64".
+        base64.StdEncoding.DecodeString(`+"`\n        c3RyaW5n\n        `"+`)
+  hexlines
+      - As b64lines, but a call to hex.DecodeString with the input's hex
+        encoding, wrapped at -w characters rounded down to an even number
+        so that each line holds whole bytes, making byte offsets easy to
+        count in review. Newlines are removed before decoding. Requires
+        importing "encoding/hex" and "strings".
+        hex.DecodeString(strings.ReplaceAll(`+"`\n        737472696e67\n        `"+`, "\n", ""))
+  words2slice
+      - Slice of the whitespace-separated words of the input, optionally
+        deduplicated (-unique) and sorted (-sort)
+        []string{"a", "string"}
+  sorted
+      - Byte slice of octets sorted in ascending order or, given multiple
+        ARGS, a slice of the ARGS sorted lexically. Ordered as by
+        slices.Sort, for use as binary search test data.
+        []byte{0x67, 0x69, 0x6e, 0x72, 0x73, 0x74} // sorted
+  alt - Regular expression matching any non-empty line of the input, with
+        each line escaped by regexp.QuoteMeta (see -longest-first). The
+        input must have at least one non-empty line.
+        `+"`(?:foo|bar\\.baz)`"+`
+  namedargs
+      - Slice of sql.Named arguments, one per NAME=VALUE line of input.
+        Values are quoted strings unless -raw-values is set. The separator
+        may be changed with -kv. Requires importing "database/sql".
+        []sql.NamedArg{
+        	sql.Named("name", "string"),
+        }
+  aes - Byte slice of octets (with leading zero) holding a nonce followed
+        by the input encrypted with AES-256-GCM using -key, and a
+        decryptAES(key, data []byte) ([]byte, error) function to decrypt
+        it. The key is never written to the output and must be supplied
+        at runtime. Requires importing "crypto/aes", "crypto/cipher", and
+        "errors".
+        []byte{0x9c, 0x1e, 0x5b, ...}
+  htmltype
+      - Quoted string converted to the html/template type named by -type
+        (default: HTML). Requires importing "html/template".
+        template.HTML("<b>string</b>")
+        Typed strings are trusted by html/template and written without
+        escaping, so only use this mode for content known to be safe.
+  cstrbytes
+      - Byte slice of octets (with leading zero) of the input terminated
+        by a NUL byte, for passing to C functions expecting a
+        NUL-terminated string, with a comment giving the string. Input
+        already ending in NUL is not terminated again unless -force-nul
+        is set.
+        []byte{0x73, 0x74, 0x00} // C string: "st"
+  table
+      - Byte slice of octets (with leading zero) opening with a block
+        comment tabulating each byte
+        []byte{
+        	/*
+        		Offset  Dec  Hex   Bin       Char
+        		0       115  0x73  01110011  s
+        		1       10   0x0a  00001010  ^J
+        	*/
+        	0x73, 0x0a,
+        }
+  auto
+      - Whichever of the modes listed by -auto-modes writes the input in
+        the least source, annotated with a comment naming it. Ties go to
+        the mode listed first.
+        []byte("string") // auto: bs
+  scantest
+      - Struct literal pairing the input with the tokens a bufio.Scanner
+        reads from it when splitting as given by -on, for testing split
+        functions
+        struct {
+        	Input  []byte
+        	Tokens []string
+        }{
+        	Input:  []byte("a\nb\n"),
+        	Tokens: []string{"a", "b"},
+        }
+  fnv - FNV-1a hash of the input, as computed by hash/fnv's New32a or
+        New64a (see -bits), for switching on precomputed string hashes.
+        Given multiple ARGS, a map of each ARG's hash to the ARG instead,
+        which is an error if two ARGS have the same hash.
+        uint32(0xe40c292c)
+        map[uint32]string{
+        	0xe40c292c: "a",
+        	0xe70c2de5: "b",
+        }
+  jstruct
+      - Declarations of struct types inferred from a JSON object, followed
+        by a literal of the object's type, named by -type. Fields are
+        declared in the order of the object's members, with json tags
+        giving their names. Numbers are int (or int64 if they do not fit
+        in 32 bits) unless any is fractional, in which case they are
+        float64. Objects in arrays are unified into one struct of all
+        their fields, and nested objects are named by appending their
+        field name to -type. A null makes a struct a pointer and a string,
+        number, or boolean an interface{}, as do mixed types.
+        type T struct {
+        	Name  string   `+"`json:\"name\"`"+`
+        	Owner TOwner   `+"`json:\"owner\"`"+`
+        	Tags  []string `+"`json:\"tags\"`"+`
+        }
+
+        type TOwner struct {
+        	Id int `+"`json:\"id\"`"+`
+        }
+
+        T{
+        	Name: "x",
+        	Owner: TOwner{
+        		Id: 1,
+        	},
+        	Tags: []string{"a", "b"},
+        }
+  gob - Call of a function literal decoding a value of the type named by
+        -type from the input, an encoding/gob stream, which panics if the
+        input cannot be decoded. Requires importing "bytes" and
+        "encoding/gob".
+        func() T {
+        	var v T
+        	if err := gob.NewDecoder(bytes.NewReader([]byte{...})).Decode(&v); err != nil {
+        		panic(err)
+        	}
+        	return v
+        }()
+        Values held in interface types must have their concrete types
+        registered with gob.Register, under the same names as when they
+        were encoded, before the value is decoded. A package-level -var is
+        initialized before the package's init functions run, so either
+        register the types in a variable initializer that the -var
+        depends on, or use -once to defer decoding to first use.
+  flagdef
+      - Call of the flag package function defining the flag named by
+        -flag-name, with usage text -flag-usage and the input, with
+        surrounding space removed, as its default value. The flag's type
+        is inferred from the default: an integer defines an Int flag (or
+        Int64 if it does not fit in 32 bits), a boolean (as parsed by
+        strconv.ParseBool) a Bool flag, and anything else a String flag.
+        Requires importing "flag".
+        flag.Int("port", 8080, "Listen port")
+  jumptable
+      - With -var, byte slice packing all inputs (such as -f files) into
+        one blob indexed by a header, followed by a NAMESegments constant
+        and a NAMESegment(i int) []byte function returning input i.
+        Every header value is a little-endian uint32: the number of
+        segments, then the offset (from the start of the blob) and length
+        of each segment in order. The segments follow, concatenated.
+        Requires importing "encoding/binary".
+        var data = []byte{
+        	0x01, 0x00, 0x00, 0x00, // 1 segment
+        	0x0c, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, // segment 0: offset 12, length 2
+        	0x68, 0x69, // segment 0
+        }
+  offsets
+      - Const block of the offset of each field of the record descriptor
+        given by -fmt (as in record mode) or, if -fmt is not set, by the
+        input, followed by the size of the whole record. No data is
+        written. Fields are given as SIZE[TYPE]:NAME, as in
+        -fmt 4:magic,2:version, and every field must have a fixed size.
+        const (
+        	OffsetMagic   = 0
+        	OffsetVersion = 4
+        	SizeTotal     = 6
+        )
+  color
+      - Slice of color.RGBA of the hex color codes of the input, one per
+        line, with or without a leading #. Codes may have 3 (#rgb,
+        shorthand for #rrggbb), 6 (#rrggbb), or 8 (#rrggbbaa) digits;
+        alpha is 0xff unless given. Requires importing "image/color".
+        []color.RGBA{
+        	{0x12, 0x34, 0x56, 0xff},
+        }
+  rot13
+      - Quoted string of the input with each ASCII letter shifted -caesar
+        places (default: 13) through the alphabet, annotated with how to
+        decode it. Other bytes are unchanged. This is obfuscation, keeping
+        text out of strings(1) output, not encryption. ROT13 decodes
+        itself.
+        "Uryyb, jbeyq!" // rot13
+  regress
+      - Test function calling the function named by -target with the
+        input, failing if it panics, to check in an input found by
+        fuzzing or testing/quick as a regression test. The test is named
+        by the input's FNV-1a hash. Requires importing "testing".
+        func TestRegression1a2b3c4d(t *testing.T) {
+        	input := []byte{0x73, 0x74}
+        	defer func() {
+        		if r := recover(); r != nil {
+        			t.Fatalf(...) // reports the input and panic
+        		}
+        	}()
+        	Parse(input)
+        }
+  record
+      - Byte slice of octets grouped into records described by -fmt
+        []byte{
+        	// record 0
+        	0x73, 0x74, // magic: "st"
+        	0x72,             // version: 114
+        	0x69, 0x6e, 0x67, // payload
+        }
 
 MODEs beginning with a 0 are equivalent to those that do not, except
 that they render single-nibble bytes with a leading 0 (0x0f).
@@ -61,12 +284,356 @@ that they render single-nibble bytes with a leading 0 (0x0f).
 OPTIONS
   -s SEP        Separator (allows escape characters; default: "\n")
   -c            Trim trailing newline from standard input
+  -fmt DESC     Record descriptor for record mode, given as a comma-separated
+                list of SIZE[TYPE]:NAME fields (e.g., 2s:magic,1u:version,*:payload).
+                SIZE is a number of bytes or * for the rest of the input (last
+                field only). TYPE is x (bytes, default), s (string), u
+                (big-endian unsigned integer), or l (little-endian unsigned
+                integer).
+  -bs-inner MODE
+                Inner string mode used by bs and bsa. May be one of q, qa, or
+                x (default: q for bs, qa for bsa).
+  -var NAME     Declare a variable named NAME with the input as its value.
+                Modes decoding their data, such as b64lines, discard the
+                decoding error (var NAME, _ = ...), since it cannot occur.
+  -const NAME   Declare a constant named NAME with the input as its value.
+                Only supported by modes writing a constant: string modes and
+                fnv.
+  -difffn       Write a firstDiff(a, b []byte) int function after the output,
+                returning the offset of the first differing byte of a and b
+                (or -1 if equal). Useful for comparing a -var against a slice
+                in tests.
+  -loader NAME  Write a function named NAME returning the input as a []byte.
+                Each call of the function allocates a new copy of the input,
+                as with a []byte from go:embed, so callers may modify it.
+  -loader-string
+                Make the -loader function return a string instead.
+  -no-header    Treat the first row of csv input as data, not field names.
+  -pem-type TYPE
+                PEM block type written by pem mode (e.g., CERTIFICATE). With
+                -from-pem, the type the input's PEM block must have.
+  -from-pem     Decode the input from a PEM block and write its contents
+                (e.g., DER bytes) in the given mode.
+  -indent UNIT  Indentation written for each level of nesting in multi-line
+                output (allows escape characters; default: "\t")
+  -type TYPE    Type used by modes that produce a typed value. For htmltype,
+                one of HTML, HTMLAttr, JS, JSStr, CSS, URL, or Srcset. For
+                gob, the Go type of the decoded value. For jstruct, the name
+                of the struct type declared for the input.
+  -with-text    Follow byte modes (b, 0b, ba, 0ba, bs, bsa, x) with a line
+                comment containing the input as a quoted string, e.g.
+                []byte{0x73, 0x74} // "st"
+  -w WIDTH      Maximum line width, in characters, of b64lines and hexlines
+                (default: 76).
+  -strip-bom    Remove a leading byte-order mark from the input. A UTF-8 BOM
+                (EF BB BF) is removed. A UTF-16 BOM (FE FF or FF FE) is
+                removed and the input converted from UTF-16 to UTF-8. Input
+                without a BOM is not modified.
+  -kv SEP       Separator between names and values of namedargs input
+                (default: "=").
+  -raw-values   Write namedargs values as Go expressions instead of strings.
+  -replace-in FILE
+                Replace the value of the var or const named by -marker in the
+                Go source file FILE with the output, leaving the rest of the
+                file unchanged.
+  -marker NAME  Name of the declaration whose value -replace-in replaces.
+  -diff-only    With -replace-in, write a unified diff of the change to
+                standard output instead of modifying FILE.
+  -force-string Convert the output of string modes to string, as in
+                string("string"), making it a typed constant. This is rarely
+                needed, since an untyped string constant becomes a string
+                wherever a type is required, but prevents the constant from
+                being implicitly converted to other string types (such as a
+                named string type or a type parameter's type argument).
+                Appending + "" does not have this effect, as the result is
+                still an untyped constant.
+  -unique       Remove duplicate words from words2slice, keeping the first.
+  -sort         Sort the words of words2slice.
+  -shuffle      Shuffle the elements of words2slice, and the rows of csv, tsv,
+                and color, into an order that depends only on -seed, for
+                fixtures testing that code does not depend on input order.
+                The shuffle uses its own generator, not math/rand, so a seed
+                gives the same order on every platform and every release of
+                goquote.
+  -seed S       Seed of -shuffle, an unsigned 64-bit integer (default: 0).
+  -note-size    Follow the output with a comment giving the size of the input,
+                e.g. []byte{0x73, 0x74} // 2 bytes
+                Use -comment-style block where a line comment would be
+                awkward, or none to omit it.
+  -key KEY      Key for aes mode. 64 hexadecimal digits are used as a 256-bit
+                key; anything else is treated as a passphrase and its SHA-256
+                digest used as the key. SHA-256 is not a password-hashing
+                function, so prefer a random hexadecimal key.
+  -nonce HEX    Nonce for aes mode (12 bytes in hexadecimal). By default, a
+                random nonce is generated. Only intended for reproducible
+                output in tests: a nonce must never be used to encrypt
+                different inputs with the same key.
+  -keep CHARS   Write only the runes in CHARS verbatim in q and ql modes,
+                escaping all others as \xHH, \uHHHH, or \UHHHHHHHH. Quotes,
+                backslashes, and non-printable runes other than tab are
+                always escaped.
+  -doc-example  Write the output as a code block for a Go doc comment, with
+                each line prefixed by "//" and a tab. go doc and pkg.go.dev
+                render indented comment lines as code, provided the block is
+                separated from any surrounding text by a "//" line, as in:
+                    // Example data:
+                    //
+                    //	var data = []byte{0x73, 0x74}
+  -once NAME    Declare a variable named NAME holding a sync.OnceValue
+                function that returns the input, so that it is only
+                constructed (e.g., decoded by b64lines) on first use. Decoding
+                errors cause a panic. Requires Go 1.21 or later and importing
+                "sync".
+                    var NAME = sync.OnceValue(func() []byte {
+                    	return []byte{0x73, 0x74}
+                    })
+  -longest-first
+                Order the lines of alt from longest to shortest. Since Go
+                regular expressions prefer the leftmost alternative that
+                matches, this makes longer lines match before their prefixes.
+  -caret        Annotate control bytes in byte modes with caret notation
+                (0x1b /* ^[ */)
+  -comment-style STYLE
+                Style of the comments written by -caret, -with-text, and
+                -note-size. One of block (/* */), line (//), or none, which
+                omits them. Line comments end their line, so byte slices
+                annotated with line comments are written one line per
+                annotated byte. By default, -caret uses block comments and
+                the others line comments.
+  -serve ADDR   Instead of reading input, serve JSON-RPC 2.0 requests on ADDR
+                (host:port, or unix:PATH for a Unix socket) so that editors
+                can quote without starting a process per call. Requests are
+                read as a stream of JSON values, and each response is written
+                on its own line. The quote method takes a mode, an input, and
+                flags named without their leading dash:
+                    {"jsonrpc": "2.0", "id": 1, "method": "quote",
+                     "params": {"mode": "b", "input": "st",
+                                "flags": {"var": "data"}}}
+                    {"jsonrpc":"2.0","id":1,"result":"var data = []byte{...}"}
+                Invalid modes, flags, or input are answered with an error
+                response (code -32602). The -s, -c, -strip-bom, -f,
+                -replace-in, -marker, and -diff-only flags cannot be set by a
+                request.
+  -assert-len N With -var, check at compile time that the length of the input
+                is N bytes. Supported by b, 0b, ba, and 0ba. The length of a
+                slice is not constant, so b and 0b declare an array named by
+                -var with an Array suffix and slice it:
+                    var dataArray = [...]byte{0x73, 0x74}
+                    var data = dataArray[:]
+
+                    // The length of dataArray is checked at compile time: one of these
+                    // constants overflows if it is not 2.
+                    const (
+                    	_ = uint(len(dataArray) - 2)
+                    	_ = uint(2 - len(dataArray))
+                    )
+  -join-raw     Given multiple ARGS, write them joined by -s as a single raw
+                string instead of one expression per ARG, if the result can
+                be backquoted (it contains no backquotes, carriage returns,
+                or control characters other than tab and newline):
+                    goquote -join-raw -s '\n' q foo 'bar baz'
+                    `+"`foo\n                    bar baz`"+`
+                MODE is ignored unless the ARGS cannot be joined, in which
+                case each is written in MODE as usual. A single input is
+                always written in MODE. The joined string is a single input,
+                so it may be declared with -var, -const, -loader, or -once.
+  -auto-modes MODES
+                Comma-separated list of the modes auto chooses from (default:
+                bs,bsa,b). May include q, qa, ql, qla, r, ra, x, j, bs, bsa,
+                b, 0b, ba, 0ba, and b64lines. Listing modes of different
+                types, such as q and b, makes the type of the output depend
+                on the input.
+  -on SPLIT     How scantest splits its input: lines, words, runes, or bytes
+                to use the bufio split function of that name (default:
+                lines), or any other string to split on it as a delimiter.
+                A delimiter splits as ScanLines does on "\n", without
+                dropping carriage returns: consecutive delimiters enclose an
+                empty token, but a final delimiter does not begin one.
+  -loop         With -var, write a loop over the bytes of the variable after
+                it, as a convenience for starting on code that iterates over
+                the data. Supported by modes writing a []byte or [N]byte. The
+                loop belongs in a function, so use the output inside one or
+                move the loop there:
+                    var data = []byte{0x73, 0x74}
+
+                    for i, b := range data {
+                    	_, _ = i, b
+                    }
+  -bits N       Size of the hashes written by fnv: 32 (default) or 64.
+  -nolint LINTERS
+                Precede the declaration written by -var, -const, -loader, or
+                -once with a //nolint:LINTERS directive, so that golangci-lint
+                ignores the given linters (e.g., lll,gomnd) for it. LINTERS is
+                a comma-separated list of linter names. Since the directive
+                applies to a declaration, it requires one of those flags.
+                    //nolint:lll
+                    var data = []byte{0x73, 0x74, ...}
+  -float-fmt VERB
+                fmt verb used to write floating-point values in tsv and
+                jstruct: a percent sign, optionally followed by a + or #
+                flag and a precision (.N), and then one of the verbs e, E,
+                f, F, g, G, x, or X. By default, floats are written in the
+                shortest form that represents them exactly. A precision
+                rounds values, so the output may not equal the input.
+  -force-nul    Make cstrbytes append a NUL byte even if the input already
+                ends with one.
+  -f FILE       Read an input from FILE instead of ARGS or standard input.
+                May be repeated to read multiple inputs, in order.
+  -mapvar NAME  Declare a map variable named NAME of the contents of each -f
+                FILE, keyed by the file's base name (or, with -fullpath, its
+                path), for bundling assets without go:embed. Files are
+                written in MODE, which must write a []byte (default: b).
+                    var NAME = map[string][]byte{
+                    	"a.txt": {0x73, 0x74},
+                    }
+  -fullpath     Key -mapvar by the paths given to -f instead of base names.
+  -package NAME Begin the output with a package clause for NAME, so that with
+                a declaration (-var, -const, -loader, -once, -mapvar, or
+                -unused-ok) it is a complete Go source file. Modes requiring
+                imports still need them added.
+  -header       Begin the output with a comment marking it as generated:
+                // Code generated by goquote. DO NOT EDIT.
+  -target FUNC  Function called by the test written by regress, such as Parse
+                or pkg.Parse.
+  -keep-trailing-ws
+                Keep spaces and tabs at the ends of output lines. By default,
+                they are removed (e.g., those left by a -s ending in spaces
+                before a newline), except inside raw strings, where they are
+                part of the string's value.
+  -flag-name NAME
+                Name of the flag defined by flagdef.
+  -flag-usage TEXT
+                Usage text of the flag defined by flagdef.
+  -caesar N     Number of places rot13 shifts letters forward (default: 13).
+                Negative values shift backward. Must not be a multiple of 26.
+  -switch       Write the inputs as the cases of a switch over i, their
+                index, each returning its input, for generating a function
+                mapping indices to values:
+                    switch i {
+                    case 0:
+                    	return "first"
+                    case 1:
+                    	return "second"
+                    }
+  -split-files N
+                Instead of writing to standard output, write the input in
+                chunks of at most N bytes to the files PREFIX0.go,
+                PREFIX1.go, and so on, named by -o-prefix, each declaring
+                one chunk, and write PREFIX.go declaring the -var as their
+                concatenation. This keeps each generated file small enough
+                to edit and compile quickly. Requires -var, -package, and a
+                single input in one of the modes b, 0b, bs, or bsa. Each
+                file begins with -header, if set, and its package clause.
+                    var dataChunk0 = []byte{0x68, 0x69}
+  -o-prefix PREFIX
+                Path prefix of the files written by -split-files.
+  -fmt-cmd CMD  Pipe the output through the command CMD, such as gofumpt or
+                goimports, and write what it writes to standard output
+                instead. CMD is split into arguments on whitespace and run
+                directly, not by a shell; it must read the source from
+                standard input and write the result to standard output. If
+                the command fails, the output is written unformatted with a
+                warning. CMD is run with goquote's privileges, so only give
+                commands you trust. Not available to -serve requests.
+  -reflect-check
+                With -var and -type, write var _ TYPE = NAME after the
+                variable, so that compilation fails if the type of the
+                generated data drifts from a type declared in another file.
+                    var data = []string{"a", "b"}
+
+                    var _ Names = data
+  -golden PATH  With -var, write an updateGolden(t *testing.T) function after
+                the variable that writes it to the golden file PATH if the
+                test flag -update is set, for regenerating golden files from
+                sample data. The test package must declare the flag, as
+                    var update = flag.Bool("update", false, "update golden files")
+                and import "os" and "testing". Only supported by modes
+                writing a []byte or string.
+  -timeout-reader
+                Wrap each []byte or string expression in a reader,
+                bytes.NewReader or strings.NewReader, passed to
+                iotest.TimeoutReader, which returns iotest.ErrTimeout from
+                its second read. Requires importing "testing/iotest" and
+                "bytes" or "strings".
+                    iotest.TimeoutReader(bytes.NewReader([]byte{0x68, 0x69}))
+  -err-reader   As -timeout-reader, but wrap the reader in
+                iotest.DataErrReader, which returns io.EOF with the last
+                data read instead of by a separate read.
+  -unused-ok    Assign each input to the blank identifier (var _ = EXPR),
+                so that generated code checks that it compiles, such as a
+                data literal type-checked against its type, without
+                declaring a name that may go unused. Unlike -var, multiple
+                inputs are each declared. Calls returning an error are
+                assigned as var _, _ = EXPR.
   -h, -help     Print this usage text.
 `,
 	)
 }
 
-func write(buf *bytes.Buffer, b []byte, mode string) {
+// caret controls whether byte modes annotate control bytes with their caret
+// notation (e.g., 0x1b /* ^[ */).
+var caret = false
+
+// bsInner, if set, is the string mode wrapped by the bs and bsa modes.
+var bsInner = ""
+
+// typeName is the type used by modes that produce a typed value.
+var typeName = ""
+
+// htmlTypes are the html/template typed strings allowed by the htmltype mode.
+var htmlTypes = map[string]bool{
+	"HTML":     true,
+	"HTMLAttr": true,
+	"JS":       true,
+	"JSStr":    true,
+	"CSS":      true,
+	"URL":      true,
+	"Srcset":   true,
+}
+
+// htmlType returns the html/template type written by the htmltype mode.
+func htmlType() string {
+	if typeName == "" {
+		return "HTML"
+	}
+	if !htmlTypes[typeName] {
+		fatalf("invalid -type %q for htmltype: must be one of HTML, HTMLAttr, JS, JSStr, CSS, URL, or Srcset", typeName)
+	}
+	return typeName
+}
+
+// recordFmt is the record descriptor used by the record mode.
+var recordFmt = ""
+
+// caretNotation returns the caret notation for the control byte c (^@ through
+// ^_, and ^? for DEL). If c is not a control byte, it returns an empty string.
+func caretNotation(c byte) string {
+	if c >= 0x20 && c != 0x7f {
+		return ""
+	}
+	return "^" + string(c^0x40)
+}
+
+// indentUnit is the indentation written for each level of nesting in
+// multi-line output.
+var indentUnit = "\t"
+
+// newline writes a newline to buf followed by depth levels of indentation.
+func newline(buf *bytes.Buffer, depth int) {
+	buf.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		buf.WriteString(indentUnit)
+	}
+}
+
+// fatalf reports an error in the input or flags and exits. While serving
+// (-serve), it is replaced so that it only aborts the current request.
+var fatalf = log.Fatalf
+
+// write writes b to buf as a Go expression in the given mode. Multi-line
+// expressions are written as though their first line were indented by depth
+// levels, with nested lines indented one level further.
+func write(buf *bytes.Buffer, b []byte, mode string, depth int) {
 	var (
 		lenstr = ""
 		pad    = false
@@ -76,9 +643,17 @@ func write(buf *bytes.Buffer, b []byte, mode string) {
 loop:
 	switch mode {
 	case "", "q":
+		if keepChars != "" {
+			buf.WriteString(quoteKeep(string(b)))
+			break
+		}
 		buf.WriteString(strconv.Quote(string(b)))
 	case "qa":
 		buf.WriteString(strconv.QuoteToASCII(string(b)))
+	case joinedRawMode:
+		buf.WriteByte('`')
+		buf.Write(b)
+		buf.WriteByte('`')
 	case "ra":
 		bsmode = "qa"
 		fallthrough
@@ -96,21 +671,20 @@ loop:
 		if mode == "qla" {
 			quotefn = strconv.QuoteToASCII
 			fallback = "qa"
+		} else if keepChars != "" {
+			quotefn = quoteKeep
 		}
 		lines := strings.SplitAfter(string(b), "\n")
 		if len(lines) <= 1 {
 			mode = fallback
 			goto loop
 		}
-		lead := ""
 		for i, line := range lines {
-			line = quotefn(line)
-			buf.WriteString(lead)
-			buf.WriteString(line)
+			buf.WriteString(quotefn(line))
 			if i < len(lines)-1 {
-				buf.WriteString(" +\n")
+				buf.WriteString(" +")
+				newline(buf, depth+1)
 			}
-			lead = "\t"
 		}
 	case "x":
 		buf.WriteByte('"')
@@ -128,8 +702,11 @@ loop:
 		bsmode = "qa"
 		fallthrough
 	case "bs":
+		if bsInner != "" {
+			bsmode = bsInner
+		}
 		buf.WriteString("[]byte(")
-		write(buf, b, bsmode)
+		write(buf, b, bsmode, depth)
 		buf.WriteByte(')')
 
 	case "0ba":
@@ -144,69 +721,290 @@ loop:
 		pad = true
 		fallthrough
 	case "b":
+		style := "none"
+		if caret {
+			style = annotationStyle("block")
+		}
+		// Line comments require one line per annotated byte.
+		multiline := style == "line" && len(b) > 0
+
 		buf.WriteString("[" + lenstr + "]byte{")
-		seenFirst := false
-		for _, c := range b {
-			if seenFirst {
-				buf.WriteString(", ")
-			}
-			seenFirst = true
+		if multiline {
+			newline(buf, depth+1)
+		}
+		for i, c := range b {
 			buf.WriteString("0x")
 			h := strconv.FormatUint(uint64(c), 16)
 			if pad && len(h) < 2 {
 				buf.WriteByte('0')
 			}
 			buf.WriteString(h)
+
+			cn := caretNotation(c)
+			switch last := i == len(b)-1; {
+			case multiline:
+				buf.WriteByte(',')
+				if cn != "" {
+					buf.WriteString(lineComment(cn))
+					if !last {
+						newline(buf, depth+1)
+					}
+				} else if !last {
+					buf.WriteByte(' ')
+				}
+			case style == "block" && cn != "":
+				buf.WriteString(blockComment(cn))
+				fallthrough
+			default:
+				if !last {
+					buf.WriteString(", ")
+				}
+			}
+		}
+		if multiline {
+			newline(buf, depth)
 		}
 		buf.WriteByte('}')
+	case "record":
+		fields, err := parseRecordFormat(recordFmt)
+		if err != nil {
+			fatalf("invalid record format: %v", err)
+		}
+		writeRecords(buf, b, fields, depth)
+	case "csv":
+		writeCSV(buf, b, depth)
+	case "b64lines":
+		writeBase64Lines(buf, b)
+	case "hexlines":
+		writeHexLines(buf, b)
+	case "words2slice":
+		writeWords(buf, b)
+	case "sorted":
+		writeSorted(buf, b, depth)
+	case "alt":
+		writeAlt(buf, b, depth)
+	case "namedargs":
+		writeNamedArgs(buf, b, depth)
+	case "aes":
+		writeAES(buf, b, depth)
+	case "htmltype":
+		buf.WriteString("template." + htmlType() + "(" + strconv.Quote(string(b)) + ")")
+	case "pem":
+		writePEM(buf, b)
+	case "tsv":
+		writeTSV(buf, b, depth)
+	case "table":
+		writeTable(buf, b, depth)
+	case "gob":
+		writeGob(buf, b, depth)
+	case "auto":
+		write(buf, b, autoMode(b), depth)
+	case "scantest":
+		writeScanTest(buf, b, depth)
+	case "fnv":
+		writeFNV(buf, b, depth)
+	case "jstruct":
+		writeJSONStruct(buf, b, depth)
+	case "cstrbytes":
+		writeCString(buf, b, depth)
+	case "regress":
+		writeRegress(buf, b, depth)
+	case "flagdef":
+		writeFlagDef(buf, b)
+	case "rot13":
+		writeROT13(buf, b, depth)
+	case "color":
+		writeColors(buf, b, depth)
+	case "offsets":
+		writeOffsets(buf, b, depth)
+	case "jumptable":
+		writeJumpTable(buf, b, depth)
 	case "j": // JSON
 		p, err := json.Marshal(string(b))
 		if err != nil {
-			log.Fatalf("unable to marshal %q as JSON: %v", b, err)
+			fatalf("unable to marshal %q as JSON: %v", b, err)
 		}
 		buf.Write(p)
 	default:
-		log.Fatalf("invalid format code %q", flag.Arg(0))
+		fatalf("invalid format code %q", mode)
 	}
 }
 
 func main() {
 	sep := "\n"
 	chomp := false
+	stripBOMs := false
 	flag.CommandLine.Usage = usage
 	flag.StringVar(&sep, "s", sep, "Separator")
 	flag.BoolVar(&chomp, "c", chomp, "Chomp")
+	flag.BoolVar(&caret, "caret", caret, "Caret notation")
+	flag.StringVar(&recordFmt, "fmt", recordFmt, "Record format")
+	flag.StringVar(&bsInner, "bs-inner", bsInner, "Inner bs mode")
+	flag.StringVar(&loader, "loader", loader, "Loader function")
+	flag.BoolVar(&loaderString, "loader-string", loaderString, "Loader returns string")
+	flag.BoolVar(&noHeader, "no-header", noHeader, "CSV has no header")
+	flag.StringVar(&varName, "var", varName, "Variable name")
+	flag.BoolVar(&diffFn, "difffn", diffFn, "Write firstDiff")
+	flag.StringVar(&pemType, "pem-type", pemType, "PEM type")
+	flag.BoolVar(&fromPEM, "from-pem", fromPEM, "Decode PEM")
+	flag.StringVar(&indentUnit, "indent", indentUnit, "Indentation")
+	flag.StringVar(&typeName, "type", typeName, "Type name")
+	flag.BoolVar(&withText, "with-text", withText, "Comment with text")
+	flag.IntVar(&wrapWidth, "w", wrapWidth, "Wrap width")
+	flag.BoolVar(&stripBOMs, "strip-bom", stripBOMs, "Strip BOM")
+	flag.StringVar(&kvSep, "kv", kvSep, "Key-value separator")
+	flag.BoolVar(&rawValues, "raw-values", rawValues, "Raw values")
+	flag.StringVar(&replaceFile, "replace-in", replaceFile, "Replace in file")
+	flag.StringVar(&marker, "marker", marker, "Replacement marker")
+	flag.BoolVar(&diffOnly, "diff-only", diffOnly, "Write diff only")
+	flag.StringVar(&commentStyle, "comment-style", commentStyle, "Comment style")
+	flag.BoolVar(&forceString, "force-string", forceString, "Force string type")
+	flag.BoolVar(&uniqueElems, "unique", uniqueElems, "Unique elements")
+	flag.BoolVar(&sortElems, "sort", sortElems, "Sort elements")
+	flag.BoolVar(&shuffleElems, "shuffle", shuffleElems, "Shuffle elements")
+	flag.Uint64Var(&shuffleSeed, "seed", shuffleSeed, "Shuffle seed")
+	flag.BoolVar(&noteSize, "note-size", noteSize, "Note size")
+	flag.StringVar(&aesKey, "key", aesKey, "AES key")
+	flag.StringVar(&aesNonce, "nonce", aesNonce, "AES nonce")
+	flag.StringVar(&keepChars, "keep", keepChars, "Keep characters")
+	flag.BoolVar(&docEx, "doc-example", docEx, "Doc comment example")
+	flag.StringVar(&onceName, "once", onceName, "OnceValue name")
+	flag.BoolVar(&longestFirst, "longest-first", longestFirst, "Longest alternatives first")
+	flag.StringVar(&serveAddr, "serve", serveAddr, "Serve address")
+	flag.IntVar(&assertLen, "assert-len", assertLen, "Assert length")
+	flag.BoolVar(&joinRaw, "join-raw", joinRaw, "Join inputs as a raw string")
+	flag.StringVar(&autoModes, "auto-modes", autoModes, "Auto mode candidates")
+	flag.StringVar(&scanOn, "on", scanOn, "Scanner split")
+	flag.BoolVar(&loop, "loop", loop, "Write loop")
+	flag.IntVar(&fnvBits, "bits", fnvBits, "FNV hash size")
+	flag.StringVar(&constName, "const", constName, "Constant name")
+	flag.StringVar(&nolint, "nolint", nolint, "Ignored linters")
+	flag.StringVar(&floatFmt, "float-fmt", floatFmt, "Float format")
+	flag.BoolVar(&forceNUL, "force-nul", forceNUL, "Always append NUL")
+	flag.Var(&inputFiles, "f", "Input file")
+	flag.StringVar(&mapVar, "mapvar", mapVar, "Map variable name")
+	flag.BoolVar(&fullPath, "fullpath", fullPath, "Key map by full path")
+	flag.StringVar(&pkgName, "package", pkgName, "Package name")
+	flag.BoolVar(&genHeader, "header", genHeader, "Generated code header")
+	flag.StringVar(&regressTarget, "target", regressTarget, "Regression test target")
+	flag.BoolVar(&keepTrailingWS, "keep-trailing-ws", keepTrailingWS, "Keep trailing whitespace")
+	flag.StringVar(&flagName, "flag-name", flagName, "flagdef name")
+	flag.StringVar(&flagUsage, "flag-usage", flagUsage, "flagdef usage")
+	flag.BoolVar(&unusedOK, "unused-ok", unusedOK, "Assign to the blank identifier")
+	flag.IntVar(&caesarShift, "caesar", caesarShift, "rot13 shift")
+	flag.BoolVar(&switchCases, "switch", switchCases, "Write a switch over input indices")
+	flag.BoolVar(&timeoutReader, "timeout-reader", timeoutReader, "Wrap in iotest.TimeoutReader")
+	flag.BoolVar(&errReader, "err-reader", errReader, "Wrap in iotest.DataErrReader")
+	flag.StringVar(&goldenPath, "golden", goldenPath, "Golden file path")
+	flag.BoolVar(&reflectCheck, "reflect-check", reflectCheck, "Check the -var has -type")
+	flag.StringVar(&fmtCmd, "fmt-cmd", fmtCmd, "Formatter command")
+	flag.IntVar(&splitSize, "split-files", splitSize, "Split output files by size")
+	flag.StringVar(&outPrefix, "o-prefix", outPrefix, "Split output file prefix")
 	flag.Parse()
 
+	checkFlags()
+
 	if sep == `\0` {
 		sep = "\x00"
 	} else if u, err := strconv.Unquote(`"` + sep + `"`); err == nil {
 		sep = u
 	}
 
+	if serveAddr != "" {
+		if flag.NArg() > 0 {
+			log.Fatal("-serve does not accept a MODE or ARGS")
+		}
+		if err := serve(serveAddr); err != nil {
+			log.Fatal("Unable to serve: ", err)
+		}
+		return
+	}
+
 	mode := ""
 	argv := flag.Args()
 	if len(argv) > 0 {
 		mode, argv = argv[0], argv[1:]
 	}
 
-	var buf bytes.Buffer
-	if len(argv) == 0 {
+	var inputs [][]byte
+	if len(inputFiles) > 0 {
+		if len(argv) > 0 {
+			log.Fatal("-f cannot be used with ARGS")
+		}
+		for _, path := range inputFiles {
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if stripBOMs {
+				b = stripBOM(b)
+			}
+			inputs = append(inputs, b)
+		}
+	} else if len(argv) == 0 {
 		b, err := ioutil.ReadAll(os.Stdin)
 		if err != nil {
 			log.Fatal(err)
 		}
+		if stripBOMs {
+			b = stripBOM(b)
+		}
 		if n := len(b); chomp && n > 0 && b[n-1] == '\n' {
 			b = b[:n-1]
 		}
-		write(&buf, b, mode)
+		inputs = append(inputs, b)
 	} else {
-		for i, arg := range argv {
-			if i > 0 {
-				buf.WriteString(sep)
+		for _, arg := range argv {
+			b := []byte(arg)
+			if stripBOMs {
+				b = stripBOM(b)
 			}
-			write(&buf, []byte(arg), mode)
+			inputs = append(inputs, b)
+		}
+	}
+
+	if fromPEM {
+		for i, b := range inputs {
+			inputs[i] = decodePEM(b)
+		}
+	}
+	if mapVar == "" {
+		inputs = collectElems(inputs, mode)
+	}
+
+	if replaceFile != "" {
+		if len(inputs) > 1 {
+			log.Fatal("-replace-in requires a single input")
+		} else if framing() > 0 {
+			log.Fatal("-replace-in cannot be used with -loader, -var, -const, -once, -mapvar, or -unused-ok")
+		}
+		var out bytes.Buffer
+		if err := replaceValue(&out, inputs[0], mode); err != nil {
+			log.Fatal("Unable to replace value: ", err)
+		}
+		if _, err := out.WriteTo(os.Stdout); err != nil {
+			log.Fatal("Unable to write diff: ", err)
 		}
+		return
+	}
+
+	if splitSize > 0 {
+		if len(inputs) > 1 {
+			log.Fatal("-split-files requires a single input")
+		}
+		if err := writeSplitFiles(inputs[0], mode); err != nil {
+			log.Fatal("Unable to write split files: ", err)
+		}
+		return
+	}
+
+	var buf bytes.Buffer
+	render(&buf, inputs, mode, sep)
+
+	if fmtCmd != "" {
+		p := runFmtCmd(buf.Bytes())
+		buf.Reset()
+		buf.Write(p)
 	}
 
 	if sep == "\n" && isTTY() {
@@ -224,6 +1022,249 @@ func main() {
 	}
 }
 
+// framing returns the number of framing flags (-loader, -var, -const, -once,
+// -mapvar, and -unused-ok) that are set.
+func framing() int {
+	n := 0
+	for _, name := range []string{loader, varName, constName, onceName, mapVar} {
+		if name != "" {
+			n++
+		}
+	}
+	if unusedOK {
+		n++
+	}
+	return n
+}
+
+// checkFlags validates the flags shared by all inputs, normalizing any that
+// allow escape characters.
+func checkFlags() {
+	if loader != "" && !isIdentifier(loader) {
+		fatalf("invalid -loader name %q: must be a Go identifier", loader)
+	} else if varName != "" && !isIdentifier(varName) {
+		fatalf("invalid -var name %q: must be a Go identifier", varName)
+	} else if constName != "" && !isIdentifier(constName) {
+		fatalf("invalid -const name %q: must be a Go identifier", constName)
+	} else if mapVar != "" && !isIdentifier(mapVar) {
+		fatalf("invalid -mapvar name %q: must be a Go identifier", mapVar)
+	} else if pkgName != "" && !isIdentifier(pkgName) {
+		fatalf("invalid -package name %q: must be a Go identifier", pkgName)
+	} else if onceName != "" && !isIdentifier(onceName) {
+		fatalf("invalid -once name %q: must be a Go identifier", onceName)
+	}
+
+	if framing() > 1 {
+		fatalf("only one of -loader, -var, -const, -once, -mapvar, and -unused-ok may be used")
+	}
+
+	if assertLen < -1 {
+		fatalf("invalid -assert-len %d: must not be negative", assertLen)
+	} else if assertLen >= 0 && varName == "" {
+		fatalf("-assert-len requires -var")
+	}
+
+	if regressTarget != "" && !isTarget(regressTarget) {
+		fatalf("invalid -target %q: must be a function name, optionally qualified (pkg.Func)", regressTarget)
+	}
+
+	if flagName != "" && (strings.HasPrefix(flagName, "-") || strings.Contains(flagName, "=")) {
+		fatalf("invalid -flag-name %q: must not begin with - or contain =", flagName)
+	}
+
+	if mapVar != "" && len(inputFiles) == 0 {
+		fatalf("-mapvar requires -f")
+	} else if pkgName != "" && framing() == 0 {
+		fatalf("-package requires -var, -const, -loader, -once, -mapvar, or -unused-ok")
+	}
+
+	if timeoutReader && errReader {
+		fatalf("only one of -timeout-reader and -err-reader may be used")
+	} else if iotestReader() != "" && (loader != "" || constName != "" || onceName != "" || mapVar != "" || assertLen >= 0 || loop) {
+		fatalf("%s cannot be used with -loader, -const, -once, -mapvar, -assert-len, or -loop", readerFlag())
+	}
+
+	if caesarShift%26 == 0 {
+		fatalf("invalid -caesar %d: must not be a multiple of 26, which leaves letters unchanged", caesarShift)
+	}
+
+	if switchCases && framing() > 0 {
+		fatalf("-switch cannot be used with -loader, -var, -const, -once, -mapvar, or -unused-ok")
+	}
+
+	if loop && varName == "" {
+		fatalf("-loop requires -var")
+	}
+
+	if splitSize < 0 {
+		fatalf("invalid -split-files %d: must not be negative", splitSize)
+	} else if splitSize > 0 && (outPrefix == "" || varName == "" || pkgName == "") {
+		fatalf("-split-files requires -o-prefix, -var, and -package")
+	} else if splitSize > 0 && (assertLen >= 0 || loop || goldenPath != "" || reflectCheck || iotestReader() != "" || docEx) {
+		fatalf("-split-files cannot be used with -assert-len, -loop, -golden, -reflect-check, -timeout-reader, -err-reader, or -doc-example")
+	}
+
+	if fmtCmd != "" && strings.TrimSpace(fmtCmd) == "" {
+		fatalf("invalid -fmt-cmd %q: must name a command", fmtCmd)
+	}
+
+	if reflectCheck && varName == "" {
+		fatalf("-reflect-check requires -var")
+	} else if reflectCheck && typeName == "" {
+		fatalf("-reflect-check requires -type")
+	} else if reflectCheck && !isType(typeName) {
+		fatalf("invalid -type %q: must be a Go type", typeName)
+	}
+
+	if shuffleElems && sortElems {
+		fatalf("only one of -shuffle and -sort may be used")
+	}
+
+	if goldenPath != "" && varName == "" {
+		fatalf("-golden requires -var")
+	} else if goldenPath != "" && iotestReader() != "" {
+		fatalf("-golden cannot be used with %s", readerFlag())
+	}
+
+	if nolint != "" && !linterList.MatchString(nolint) {
+		fatalf("invalid -nolint %q: must be a comma-separated list of linter names", nolint)
+	} else if nolint != "" && framing() == 0 {
+		fatalf("-nolint requires -var, -const, -loader, -once, -mapvar, or -unused-ok")
+	}
+
+	if wrapWidth < 1 {
+		fatalf("invalid -w %d: must be at least 1", wrapWidth)
+	}
+
+	if floatFmt != "" && !floatVerb.MatchString(floatFmt) {
+		fatalf("invalid -float-fmt %q: must be a single float verb (e, E, f, F, g, G, x, or X) with optional +, #, and precision", floatFmt)
+	}
+
+	if fnvBits != 32 && fnvBits != 64 {
+		fatalf("invalid -bits %d: must be 32 or 64", fnvBits)
+	}
+
+	if kvSep == "" {
+		fatalf("invalid -kv: separator must not be empty")
+	}
+
+	if scanOn == "" {
+		fatalf("invalid -on: delimiter must not be empty")
+	}
+
+	switch commentStyle {
+	case "", "block", "line", "none":
+	default:
+		fatalf("invalid -comment-style %q: must be one of block, line, or none", commentStyle)
+	}
+
+	switch bsInner {
+	case "", "q", "qa", "x":
+	default:
+		fatalf("invalid -bs-inner mode %q: must be one of q, qa, or x", bsInner)
+	}
+
+	for _, m := range strings.Split(autoModes, ",") {
+		if !autoCandidates[m] {
+			fatalf("invalid -auto-modes mode %q: must be one of q, qa, ql, qla, r, ra, x, j, bs, bsa, b, 0b, ba, 0ba, or b64lines", m)
+		}
+	}
+
+	if u, err := strconv.Unquote(`"` + indentUnit + `"`); err == nil {
+		indentUnit = u
+	}
+	if strings.Trim(indentUnit, " \t") != "" {
+		fatalf("invalid -indent %q: must contain only spaces and tabs", indentUnit)
+	}
+}
+
+// render writes inputs to buf in the given mode, separated by sep, followed by
+// any helper functions the output requires.
+func render(buf *bytes.Buffer, inputs [][]byte, mode, sep string) {
+	if mode == joinedRawMode {
+		fatalf("invalid format code %q", mode)
+	}
+
+	writeFileHeader(buf)
+
+	if aesNonce != "" && len(inputs) > 1 {
+		fatalf("-nonce cannot be used with multiple inputs")
+	}
+
+	if mapVar != "" {
+		writeMapVar(buf, inputs, mode)
+		inputs = nil
+	}
+
+	if switchCases {
+		writeSwitch(buf, inputs, mode, 0)
+		inputs = nil
+	}
+
+	if joinRaw && len(inputs) > 1 {
+		if joined, ok := joinRawInputs(inputs, sep); ok {
+			inputs, mode = joined, joinedRawMode
+		}
+	}
+
+	if loader != "" && len(inputs) > 1 {
+		fatalf("-loader requires a single input")
+	} else if varName != "" && len(inputs) > 1 {
+		fatalf("-var requires a single input")
+	} else if constName != "" && len(inputs) > 1 {
+		fatalf("-const requires a single input")
+	} else if onceName != "" && len(inputs) > 1 {
+		fatalf("-once requires a single input")
+	}
+
+	comment := ""
+	for i, b := range inputs {
+		if i > 0 {
+			writeSep(buf, sep, comment)
+		}
+		comment = writeFramed(buf, b, mode, 0)
+	}
+	if comment != "" {
+		buf.WriteString(lineComment(comment))
+	}
+
+	if loop {
+		buf.WriteString("\n\n")
+		writeLoop(buf, inputs[0], mode, 0)
+	}
+	if mode == "jumptable" {
+		buf.WriteString("\n\n")
+		writeJumpTableAccessor(buf, inputs[0], 0)
+	}
+	if reflectCheck {
+		buf.WriteString("\n\n")
+		writeTypeCheck(buf, 0)
+	}
+	if goldenPath != "" {
+		buf.WriteString("\n\n")
+		writeGolden(buf, inputs[0], mode, 0)
+	}
+	if diffFn {
+		buf.WriteString("\n\n")
+		writeFirstDiff(buf, 0)
+	}
+	if mode == "aes" {
+		buf.WriteString("\n\n")
+		writeDecryptAES(buf, 0)
+	}
+
+	if docEx {
+		p := docExample(buf.Bytes())
+		buf.Reset()
+		buf.Write(p)
+	}
+	if !keepTrailingWS {
+		p := trimTrailingSpace(buf.Bytes())
+		buf.Reset()
+		buf.Write(p)
+	}
+}
+
 // isTTY attempts to determine whether the current stdout refers to a terminal.
 func isTTY() bool {
 	fi, err := os.Stdout.Stat()
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// catchFatal returns the message passed to fatalf by f, or an empty string if f
+// returns without calling it.
+func catchFatal(f func()) (msg string) {
+	defer func(prev func(string, ...interface{})) {
+		fatalf = prev
+		if v := recover(); v != nil {
+			qe, ok := v.(quoteError)
+			if !ok {
+				panic(v)
+			}
+			msg = string(qe)
+		}
+	}(fatalf)
+	fatalf = func(format string, args ...interface{}) {
+		panic(quoteError(fmt.Sprintf(format, args...)))
+	}
+	f()
+	return ""
+}
+
+// writeString returns b written in mode.
+func writeString(b []byte, mode string) string {
+	var buf bytes.Buffer
+	write(&buf, b, mode, 0)
+	return buf.String()
+}
+
+// renderString returns the output of render for inputs written in mode,
+// separated by newlines.
+func renderString(mode string, inputs ...string) string {
+	p := make([][]byte, len(inputs))
+	for i, in := range inputs {
+		p[i] = []byte(in)
+	}
+	var buf bytes.Buffer
+	render(&buf, collectElems(p, mode), mode, "\n")
+	elems = nil
+	return buf.String()
+}
+
+func TestCaretNotation(t *testing.T) {
+	cases := []struct {
+		c    byte
+		want string
+	}{
+		{0x00, "^@"},
+		{0x1b, "^["},
+		{0x1f, "^_"},
+		{0x20, ""},
+		{0x7f, "^?"},
+	}
+	for _, c := range cases {
+		if got := caretNotation(c.c); got != c.want {
+			t.Errorf("caretNotation(%#02x) = %q; want %q", c.c, got, c.want)
+		}
+	}
+}
+
+func TestRawFallback(t *testing.T) {
+	cases := []struct {
+		mode, in, want string
+	}{
+		{"r", "héllo", "`héllo`"},
+		{"ra", "héllo", "`héllo`"},
+		{"r", "a`é", "\"a`é\""},
+		{"ra", "a`é", "\"a`\\u00e9\""},
+		{"r", "a\x01é", "\"a\\x01é\""},
+		{"ra", "a\x01é", "\"a\\x01\\u00e9\""},
+	}
+	for _, c := range cases {
+		if got := writeString([]byte(c.in), c.mode); got != c.want {
+			t.Errorf("write(%q, %q) = %s; want %s", c.in, c.mode, got, c.want)
+		}
+	}
+}
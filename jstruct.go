@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/format"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// jsonValue is a parsed JSON value. Unlike a value unmarshaled into an
+// interface{}, it preserves the order of object members, so that struct fields
+// are declared in the order they appear in the input.
+type jsonValue struct {
+	// kind is the first letter of the value's type: o (object), a (array),
+	// s (string), n (number), b (boolean), or z (null).
+	kind byte
+	// text is a string's contents or the literal text of a number or
+	// boolean.
+	text string
+	// keys and vals are the names and values of an object's members, or
+	// vals the elements of an array.
+	keys []string
+	vals []*jsonValue
+}
+
+// parseJSON parses b as a single JSON value.
+func parseJSON(b []byte) (*jsonValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	v, err := decodeJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// decodeJSON decodes the next JSON value from dec. As with encoding/json, an
+// object member replaces any earlier member of the same name.
+func decodeJSON(dec *json.Decoder) (*jsonValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		v := &jsonValue{kind: 'a'}
+		if tok == '{' {
+			v.kind = 'o'
+		}
+		for dec.More() {
+			key := ""
+			if v.kind == 'o' {
+				t, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key = t.(string)
+			}
+			e, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			if v.kind == 'o' {
+				if i := indexString(v.keys, key); i != -1 {
+					v.vals[i] = e
+					continue
+				}
+				v.keys = append(v.keys, key)
+			}
+			v.vals = append(v.vals, e)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case string:
+		return &jsonValue{kind: 's', text: tok}, nil
+	case json.Number:
+		return &jsonValue{kind: 'n', text: tok.String()}, nil
+	case bool:
+		return &jsonValue{kind: 'b', text: strconv.FormatBool(tok)}, nil
+	}
+	return &jsonValue{kind: 'z'}, nil
+}
+
+// indexString returns the index of the first s in list, or -1 if it is not
+// present.
+func indexString(list []string, s string) int {
+	for i, e := range list {
+		if e == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// jsonType is a Go type inferred from JSON values.
+type jsonType struct {
+	// kind is one of string, int, int64, float64, bool, struct, slice,
+	// interface{}, or null, for a type only inferred from null values.
+	kind string
+	// name is the name of a struct type, and ptr whether it is referred to
+	// by pointer because some of its values are null.
+	name string
+	ptr  bool
+	// keys, fields, and types are the JSON member names, Go field names,
+	// and types of a struct's fields.
+	keys   []string
+	fields []string
+	types  []*jsonType
+	// elem is the element type of a slice, or nil if no elements have been
+	// seen.
+	elem *jsonType
+}
+
+// goType returns t as a Go type.
+func (t *jsonType) goType() string {
+	switch t.kind {
+	case "struct":
+		if t.ptr {
+			return "*" + t.name
+		}
+		return t.name
+	case "slice":
+		return "[]" + t.elem.goType()
+	}
+	return t.kind
+}
+
+// inferJSON returns the type of v. Objects are inferred as structs named name,
+// with the types of their members named by appending each member's field name.
+func inferJSON(v *jsonValue, name string) *jsonType {
+	switch v.kind {
+	case 'o':
+		t := &jsonType{kind: "struct", name: name}
+		for i, key := range v.keys {
+			field := exportedName(key)
+			if field == "" {
+				field = "F" + strconv.Itoa(i)
+			}
+			t.keys = append(t.keys, key)
+			t.types = append(t.types, inferJSON(v.vals[i], name+field))
+		}
+		return t
+	case 'a':
+		t := &jsonType{kind: "slice"}
+		for _, e := range v.vals {
+			t.elem = unifyJSON(t.elem, inferJSON(e, name))
+		}
+		return t
+	case 's':
+		return &jsonType{kind: "string"}
+	case 'b':
+		return &jsonType{kind: "bool"}
+	case 'z':
+		return &jsonType{kind: "null"}
+	}
+	if n, err := strconv.ParseInt(v.text, 10, 64); err != nil {
+		return &jsonType{kind: "float64"}
+	} else if n != int64(int32(n)) {
+		return &jsonType{kind: "int64"}
+	}
+	return &jsonType{kind: "int"}
+}
+
+// numericRank orders the numeric kinds of jsonType by the values they can
+// hold, or returns 0 for other kinds.
+var numericRank = map[string]int{"int": 1, "int64": 2, "float64": 3}
+
+// unifyJSON returns a type that holds the values of both a and b, either of
+// which may be nil. Structs are unified by combining their fields, slices by
+// unifying their elements, and numbers by widening them. A struct that may be
+// null becomes a pointer. Any other mix of types, including a null and a
+// string, number, or boolean, is unified as interface{}.
+func unifyJSON(a, b *jsonType) *jsonType {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.kind == "null":
+		return nullableJSON(b)
+	case b.kind == "null":
+		return nullableJSON(a)
+	case numericRank[a.kind] > 0 && numericRank[b.kind] > 0:
+		if numericRank[b.kind] > numericRank[a.kind] {
+			return b
+		}
+		return a
+	case a.kind != b.kind:
+		return &jsonType{kind: "interface{}"}
+	case a.kind == "struct":
+		for i, key := range b.keys {
+			if j := indexString(a.keys, key); j != -1 {
+				a.types[j] = unifyJSON(a.types[j], b.types[i])
+			} else {
+				a.keys = append(a.keys, key)
+				a.types = append(a.types, b.types[i])
+			}
+		}
+		a.ptr = a.ptr || b.ptr
+	case a.kind == "slice":
+		a.elem = unifyJSON(a.elem, b.elem)
+	}
+	return a
+}
+
+// nullableJSON returns a type holding the values of t and null.
+func nullableJSON(t *jsonType) *jsonType {
+	switch t.kind {
+	case "struct":
+		t.ptr = true
+		return t
+	case "slice", "interface{}", "null":
+		return t
+	}
+	return &jsonType{kind: "interface{}"}
+}
+
+// resolveJSON completes t once all values have been inferred: types of only
+// null values, and slices of no elements, become interface{}, and struct fields
+// are named. Each struct is appended to structs, in the order that their
+// declarations are written, and given a unique name.
+func resolveJSON(t *jsonType, structs []*jsonType, names map[string]bool) []*jsonType {
+	switch t.kind {
+	case "null":
+		t.kind = "interface{}"
+	case "slice":
+		if t.elem == nil {
+			t.elem = &jsonType{kind: "interface{}"}
+		}
+		structs = resolveJSON(t.elem, structs, names)
+	case "struct":
+		for base, i := t.name, 2; names[t.name]; i++ {
+			t.name = base + strconv.Itoa(i)
+		}
+		names[t.name] = true
+		t.fields = fieldNames(t.keys)
+		structs = append(structs, t)
+		for _, ft := range t.types {
+			structs = resolveJSON(ft, structs, names)
+		}
+	}
+	return structs
+}
+
+// inferJSONInput parses b, which must be a JSON object, and returns it with
+// its inferred type, named by -type, and the structs declared by that type.
+func inferJSONInput(b []byte) (*jsonValue, *jsonType, []*jsonType) {
+	if !isIdentifier(typeName) {
+		fatalf("jstruct mode requires a -type that is a Go identifier")
+	}
+	v, err := parseJSON(b)
+	if err != nil {
+		fatalf("jstruct: unable to parse JSON: %v", err)
+	}
+	if v.kind != 'o' {
+		fatalf("jstruct: input must be a JSON object")
+	}
+	t := inferJSON(v, typeName)
+	return v, t, resolveJSON(t, nil, map[string]bool{})
+}
+
+// jsonTag returns the struct tag naming the JSON member key.
+func jsonTag(key string) string {
+	tag := "json:" + strconv.Quote(key)
+	if strconv.CanBackquote(tag) {
+		return "`" + tag + "`"
+	}
+	return strconv.Quote(tag)
+}
+
+// jsonLiteral returns v as an unformatted Go expression of type t. If elide is
+// true, the type of a struct literal is omitted, as allowed for the elements
+// of a slice.
+func jsonLiteral(v *jsonValue, t *jsonType, elide bool) string {
+	if v.kind == 'z' {
+		return "nil"
+	}
+	switch t.kind {
+	case "struct":
+		lit := ""
+		if !elide {
+			if t.ptr {
+				lit = "&"
+			}
+			lit += t.name
+		}
+		lit += "{"
+		for i, key := range v.keys {
+			if v.vals[i].kind == 'z' {
+				continue
+			}
+			j := indexString(t.keys, key)
+			lit += "\n" + t.fields[j] + ": " + jsonLiteral(v.vals[i], t.types[j], false) + ","
+		}
+		if strings.HasSuffix(lit, ",") {
+			lit += "\n"
+		}
+		return lit + "}"
+	case "slice":
+		elems := make([]string, len(v.vals))
+		multiline := false
+		for i, e := range v.vals {
+			elems[i] = jsonLiteral(e, t.elem, true)
+			multiline = multiline || e.kind == 'o' || e.kind == 'a'
+		}
+		if multiline {
+			return t.goType() + "{\n" + strings.Join(elems, ",\n") + ",\n}"
+		}
+		return t.goType() + "{" + strings.Join(elems, ", ") + "}"
+	case "interface{}":
+		return jsonAnyLiteral(v)
+	case "string":
+		return strconv.Quote(v.text)
+	case "float64":
+		return jsonFloat(v.text)
+	}
+	return v.text
+}
+
+// jsonAnyLiteral returns v as an unformatted Go expression of the type that
+// encoding/json unmarshals it as into an interface{}.
+func jsonAnyLiteral(v *jsonValue) string {
+	switch v.kind {
+	case 'o':
+		lit := "map[string]interface{}{"
+		for i, key := range v.keys {
+			lit += "\n" + strconv.Quote(key) + ": " + jsonAnyLiteral(v.vals[i]) + ","
+		}
+		if len(v.keys) > 0 {
+			lit += "\n"
+		}
+		return lit + "}"
+	case 'a':
+		lit := "[]interface{}{"
+		for i, e := range v.vals {
+			if i > 0 {
+				lit += ", "
+			}
+			lit += jsonAnyLiteral(e)
+		}
+		return lit + "}"
+	case 's':
+		return strconv.Quote(v.text)
+	case 'n':
+		return "float64(" + jsonFloat(v.text) + ")"
+	case 'b':
+		return v.text
+	}
+	return "nil"
+}
+
+// jsonFloat returns the JSON number text as a Go floating-point literal.
+func jsonFloat(text string) string {
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(f, 0) {
+		fatalf("jstruct: number %s does not fit in a float64", text)
+	}
+	return formatFloat(f)
+}
+
+// gofmtSource returns src, prefixed by prefix, formatted as a Go source file
+// with the package clause and prefix removed.
+func gofmtSource(prefix, src string) string {
+	const pkg = "package p\n\n"
+	p, err := format.Source([]byte(pkg + prefix + src))
+	if err != nil {
+		fatalf("jstruct: unable to format output: %v", err)
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(string(p), pkg+prefix), "\n")
+}
+
+// writeJSONTypes writes the declarations of the struct types inferred from b,
+// a JSON object, by the jstruct mode. The type of the object is named by -type,
+// and the types of nested objects by appending their field names to it.
+func writeJSONTypes(buf *bytes.Buffer, b []byte, depth int) {
+	_, _, structs := inferJSONInput(b)
+	var src strings.Builder
+	for i, t := range structs {
+		if i > 0 {
+			src.WriteString("\n")
+		}
+		src.WriteString("type " + t.name + " struct {\n")
+		for j, field := range t.fields {
+			src.WriteString(field + " " + t.types[j].goType() + " " + jsonTag(t.keys[j]) + "\n")
+		}
+		src.WriteString("}\n")
+	}
+	writeSource(buf, gofmtSource("", src.String()), depth)
+}
+
+// writeJSONStruct writes b, a JSON object, as a literal of the struct type
+// named by -type that is declared by writeJSONTypes.
+func writeJSONStruct(buf *bytes.Buffer, b []byte, depth int) {
+	v, t, _ := inferJSONInput(b)
+	writeSource(buf, gofmtSource("var _ = ", jsonLiteral(v, t, false)), depth)
+}
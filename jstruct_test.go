@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// jsonTypeOf returns the Go type inferred from the JSON value s, named T.
+func jsonTypeOf(t *testing.T, s string) *jsonType {
+	v, err := parseJSON([]byte(s))
+	if err != nil {
+		t.Fatalf("parseJSON(%s): %v", s, err)
+	}
+	return inferJSON(v, "T")
+}
+
+func TestInferJSON(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{`"s"`, "string"},
+		{`true`, "bool"},
+		{`null`, "null"},
+		{`1`, "int"},
+		{`-2147483648`, "int"},
+		{`2147483648`, "int64"},
+		{`1.5`, "float64"},
+		{`1e3`, "float64"},
+		{`99999999999999999999`, "float64"},
+		{`{}`, "T"},
+		{`[]`, "[]null"},
+		{`[1, 2]`, "[]int"},
+		{`[1, 5000000000]`, "[]int64"},
+		{`[1, 5000000000, 0.5]`, "[]float64"},
+		{`[0.5, 1]`, "[]float64"},
+		{`[{}, null]`, "[]*T"},
+		{`[null, {}]`, "[]*T"},
+		{`[[1], null]`, "[][]int"},
+		{`[1, null]`, "[]interface{}"},
+		{`["a", null]`, "[]interface{}"},
+		{`[null, null]`, "[]null"},
+		{`[1, "a"]`, "[]interface{}"},
+		{`[true, 1]`, "[]interface{}"},
+		{`[{}, []]`, "[]interface{}"},
+		{`[[1], ["a"]]`, "[][]interface{}"},
+	}
+	for _, c := range cases {
+		typ := jsonTypeOf(t, c.in)
+		// The element type of an empty slice is nil until resolved.
+		if typ.kind == "slice" && typ.elem == nil {
+			typ.elem = &jsonType{kind: "null"}
+		}
+		if got := typ.goType(); got != c.want {
+			t.Errorf("inferJSON(%s) = %s; want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestUnifyJSONStructs(t *testing.T) {
+	typ := jsonTypeOf(t, `[{"a": 1, "b": "x"}, {"c": true, "a": 1.5}, {"b": null, "d": {}}, null]`)
+	elem := typ.elem
+	if got, want := elem.goType(), "*T"; got != want {
+		t.Fatalf("element type = %s; want %s", got, want)
+	}
+
+	want := []struct{ key, typ string }{
+		{"a", "float64"},
+		{"b", "interface{}"},
+		{"c", "bool"},
+		{"d", "TD"},
+	}
+	if len(elem.keys) != len(want) {
+		t.Fatalf("fields = %q; want %d fields", elem.keys, len(want))
+	}
+	for i, w := range want {
+		if key, typ := elem.keys[i], elem.types[i].goType(); key != w.key || typ != w.typ {
+			t.Errorf("field %d = %s %s; want %s %s", i, key, typ, w.key, w.typ)
+		}
+	}
+
+	if got := unifyJSON(nil, nil); got != nil {
+		t.Errorf("unifyJSON(nil, nil) = %v; want nil", got)
+	}
+	if got := unifyJSON(nil, &jsonType{kind: "int"}).goType(); got != "int" {
+		t.Errorf("unifyJSON(nil, int) = %s; want int", got)
+	}
+}
+
+func TestResolveJSONNames(t *testing.T) {
+	typ := jsonTypeOf(t, `{"a": {"b": {}}, "aB": {}, "e": [], "n": null}`)
+	structs := resolveJSON(typ, nil, map[string]bool{})
+
+	var names []string
+	for _, s := range structs {
+		names = append(names, s.name)
+	}
+	if got, want := names, []string{"T", "TA", "TAB", "TAB2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("struct names = %q; want %q", got, want)
+	}
+	if got, want := typ.types[2].goType(), "[]interface{}"; got != want {
+		t.Errorf("type of empty array = %s; want %s", got, want)
+	}
+	if got, want := typ.types[3].goType(), "interface{}"; got != want {
+		t.Errorf("type of null = %s; want %s", got, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	defer func(prev string) { typeName = prev }(typeName)
+	typeName = "T"
+
+	const in = `{"items": [{"id": 1, "name": "a"}, {"id": 5000000000, "tags": ["x"]}, null], "mixed": [1, "two", true], "parent": {"t": 1.5}}`
+
+	const wantTypes = "type T struct {\n" +
+		"\tItems  []*TItems     `json:\"items\"`\n" +
+		"\tMixed  []interface{} `json:\"mixed\"`\n" +
+		"\tParent TParent       `json:\"parent\"`\n" +
+		"}\n" +
+		"\n" +
+		"type TItems struct {\n" +
+		"\tId   int64    `json:\"id\"`\n" +
+		"\tName string   `json:\"name\"`\n" +
+		"\tTags []string `json:\"tags\"`\n" +
+		"}\n" +
+		"\n" +
+		"type TParent struct {\n" +
+		"\tT float64 `json:\"t\"`\n" +
+		"}"
+	var buf bytes.Buffer
+	writeJSONTypes(&buf, []byte(in), 0)
+	if got := buf.String(); got != wantTypes {
+		t.Errorf("writeJSONTypes() =\n%s\nwant\n%s", got, wantTypes)
+	}
+
+	const wantStruct = "T{\n" +
+		"\tItems: []*TItems{\n" +
+		"\t\t{\n" +
+		"\t\t\tId:   1,\n" +
+		"\t\t\tName: \"a\",\n" +
+		"\t\t},\n" +
+		"\t\t{\n" +
+		"\t\t\tId:   5000000000,\n" +
+		"\t\t\tTags: []string{\"x\"},\n" +
+		"\t\t},\n" +
+		"\t\tnil,\n" +
+		"\t},\n" +
+		"\tMixed: []interface{}{float64(1), \"two\", true},\n" +
+		"\tParent: TParent{\n" +
+		"\t\tT: 1.5,\n" +
+		"\t},\n" +
+		"}"
+	buf.Reset()
+	writeJSONStruct(&buf, []byte(in), 0)
+	if got := buf.String(); got != wantStruct {
+		t.Errorf("writeJSONStruct() =\n%s\nwant\n%s", got, wantStruct)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// jumpTable returns segs packed into a single blob: a header giving the number
+// of segments and the offset and length of each, followed by the segments
+// concatenated in order. Every header value is a little-endian uint32, and
+// offsets are from the start of the blob:
+//
+//	count
+//	offset[0] length[0]
+//	...
+//	offset[count-1] length[count-1]
+//	data
+func jumpTable(segs [][]byte) []byte {
+	size := 4 + 8*len(segs)
+	for _, seg := range segs {
+		size += len(seg)
+	}
+	if uint64(size) > math.MaxUint32 {
+		fatalf("jumptable: %d bytes exceeds the 4 GiB limit of its offsets", size)
+	}
+
+	blob := make([]byte, 4+8*len(segs), size)
+	binary.LittleEndian.PutUint32(blob, uint32(len(segs)))
+	for i, seg := range segs {
+		binary.LittleEndian.PutUint32(blob[4+8*i:], uint32(len(blob)))
+		binary.LittleEndian.PutUint32(blob[8+8*i:], uint32(len(seg)))
+		blob = append(blob, seg...)
+	}
+	return blob
+}
+
+// writeJumpTable writes the inputs, held by elems if there are multiple, as the
+// []byte of their jump table (see jumpTable). The header is written one entry
+// per line and each segment's data on lines of its own, each commented, so
+// that a change to one segment only changes its own lines and its entry.
+func writeJumpTable(buf *bytes.Buffer, b []byte, depth int) {
+	if varName == "" {
+		fatalf("jumptable mode requires -var to name the table used by its accessor")
+	}
+	segs := elems
+	if segs == nil {
+		segs = [][]byte{b}
+	}
+	blob := jumpTable(segs)
+
+	var lines []byteLine
+	if len(segs) == 1 {
+		lines = append(lines, byteLine{blob[:4], "1 segment"})
+	} else {
+		lines = append(lines, byteLine{blob[:4], strconv.Itoa(len(segs)) + " segments"})
+	}
+	for i, seg := range segs {
+		off := binary.LittleEndian.Uint32(blob[4+8*i:])
+		lines = append(lines, byteLine{blob[4+8*i : 12+8*i], fmt.Sprintf("segment %d: offset %d, length %d", i, off, len(seg))})
+	}
+	for i, seg := range segs {
+		comment := "segment " + strconv.Itoa(i)
+		if len(seg) == 0 {
+			lines = append(lines, byteLine{nil, comment + " (empty)"})
+		}
+		for len(seg) > 0 {
+			n := recordLineBytes
+			if n > len(seg) {
+				n = len(seg)
+			}
+			lines = append(lines, byteLine{seg[:n], comment})
+			seg, comment = seg[n:], ""
+		}
+	}
+	writeByteLines(buf, lines, depth)
+}
+
+// writeJumpTableAccessor writes the count of segments in the -var declared by
+// the jumptable mode and a function returning a segment of it by index.
+func writeJumpTableAccessor(buf *bytes.Buffer, b []byte, depth int) {
+	n := 1
+	if elems != nil {
+		n = len(elems)
+	}
+	name := varName
+	writeSource(buf, `// `+name+`Segments is the number of segments in `+name+`.
+const `+name+`Segments = `+strconv.Itoa(n)+`
+
+// `+name+`Segment returns segment i of `+name+`, which must be in the range
+// [0, `+name+`Segments).
+func `+name+`Segment(i int) []byte {
+	off := binary.LittleEndian.Uint32(`+name+`[4+8*i:])
+	n := binary.LittleEndian.Uint32(`+name+`[8+8*i:])
+	return `+name+`[off : off+n : off+n]
+}`, depth)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/binary"
+	"go/format"
+	"testing"
+)
+
+func TestJumpTable(t *testing.T) {
+	segs := [][]byte{[]byte("a"), []byte("bc"), {}, []byte("d")}
+	blob := jumpTable(segs)
+	if n := binary.LittleEndian.Uint32(blob); n != uint32(len(segs)) {
+		t.Fatalf("count = %d; want %d", n, len(segs))
+	}
+	for i, seg := range segs {
+		off := binary.LittleEndian.Uint32(blob[4+8*i:])
+		n := binary.LittleEndian.Uint32(blob[8+8*i:])
+		if got := string(blob[off : off+n]); got != string(seg) {
+			t.Errorf("segment %d = %q; want %q", i, got, seg)
+		}
+	}
+}
+
+func TestWriteJumpTableGofmt(t *testing.T) {
+	defer func(prev string) { varName = prev }(varName)
+	varName = "d"
+
+	src := "package p\n\n" + renderString("jumptable", "a", "bc", "", "d", "efghijklmnopqrstuvwxyz") + "\n"
+	if got, err := format.Source([]byte(src)); err != nil {
+		t.Errorf("jumptable: %v\n%s", err, src)
+	} else if string(got) != src {
+		t.Errorf("jumptable output is not gofmt-formatted:\n%s\ngofmt:\n%s", src, got)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// keepChars, if set, is the set of runes that the q and ql modes write
+// verbatim. All other runes are escaped.
+var keepChars = ""
+
+// quoteKeep returns s as a double-quoted Go string in which only the runes in
+// -keep are written verbatim. Every other rune is escaped, as are quotes,
+// backslashes, and non-printable runes (other than tab) even if they are in
+// -keep, since they cannot appear verbatim in a Go string. Bytes that are not
+// valid UTF-8 are escaped as \xHH.
+func quoteKeep(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+			i++
+			continue
+		}
+		i += size
+
+		if r != '"' && r != '\\' && (r == '\t' || strconv.IsPrint(r)) && strings.ContainsRune(keepChars, r) {
+			sb.WriteRune(r)
+			continue
+		}
+
+		switch r {
+		case '\a':
+			sb.WriteString(`\a`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\v':
+			sb.WriteString(`\v`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		default:
+			switch {
+			case r < utf8.RuneSelf:
+				fmt.Fprintf(&sb, `\x%02x`, r)
+			case r <= 0xffff:
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			default:
+				fmt.Fprintf(&sb, `\U%08x`, r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
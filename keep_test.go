@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestQuoteKeep(t *testing.T) {
+	defer func(prev string) { keepChars = prev }(keepChars)
+	keepChars = "abcé\"\\\t"
+
+	cases := []struct {
+		in, want string
+	}{
+		{"abc", `"abc"`},
+		{"abxd", `"ab\x78\x64"`},
+		{"café", `"ca\x66é"`},
+		{"日é", `"\u65e5é"`},
+		{"a😀", `"a\U0001f600"`},
+		// Quotes and backslashes are escaped even if kept; tab is kept.
+		{"\"\\\t", `"\"\\` + "\t" + `"`},
+		{"a\nb", `"a\nb"`},
+		{"a\xffb", `"a\xffb"`},
+	}
+	for _, c := range cases {
+		got := quoteKeep(c.in)
+		if got != c.want {
+			t.Errorf("quoteKeep(%q) = %s; want %s", c.in, got, c.want)
+		}
+		if s, err := strconv.Unquote(got); err != nil || s != c.in {
+			t.Errorf("quoteKeep(%q) = %s, which unquotes to %q, %v", c.in, got, s, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// wrapWidth is the maximum width, in characters, of the lines written by the
+// b64lines and hexlines modes.
+var wrapWidth = 76
+
+// splitLines returns the lines of b with their line endings (\n or \r\n)
+// removed. A final line ending does not begin an empty line.
+func splitLines(b []byte) []string {
+	s := strings.TrimSuffix(string(b), "\n")
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// writeWrapped writes s to buf as a backquoted string, broken into lines of at
+// most width characters. The string begins with a newline so that every line
+// of s starts at the beginning of a line. Lines are never indented, since
+// indentation would become part of the string.
+func writeWrapped(buf *bytes.Buffer, s string, width int) {
+	buf.WriteByte('`')
+	for len(s) > 0 {
+		n := width
+		if n > len(s) {
+			n = len(s)
+		}
+		buf.WriteByte('\n')
+		buf.WriteString(s[:n])
+		s = s[n:]
+	}
+	buf.WriteString("\n`")
+}
+
+// writeBase64Lines writes b as a call to base64.StdEncoding.DecodeString with
+// the base64 encoding of b as its argument, wrapped at -w characters per line.
+// Since the decoder ignores newlines, the wrapped string decodes to b, and a
+// small change to b only changes the lines it falls on.
+func writeBase64Lines(buf *bytes.Buffer, b []byte) {
+	buf.WriteString("base64.StdEncoding.DecodeString(")
+	if len(b) == 0 {
+		buf.WriteString("``)")
+		return
+	}
+	writeWrapped(buf, base64.StdEncoding.EncodeToString(b), wrapWidth)
+	buf.WriteByte(')')
+}
+
+// writeHexLines writes b as a call to hex.DecodeString with the hex encoding of
+// b as its argument, wrapped at -w characters per line, rounded down to an even
+// number so that no byte is split across lines. Unlike the base64 decoder, the
+// hex decoder does not ignore newlines, so they are removed before decoding.
+func writeHexLines(buf *bytes.Buffer, b []byte) {
+	if len(b) == 0 {
+		buf.WriteString("hex.DecodeString(``)")
+		return
+	}
+	width := wrapWidth &^ 1
+	if width < 2 {
+		width = 2
+	}
+	buf.WriteString("hex.DecodeString(strings.ReplaceAll(")
+	writeWrapped(buf, hex.EncodeToString(b), width)
+	buf.WriteString(`, "\n", ""))`)
+}
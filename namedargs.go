@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// namedargs flags.
+var (
+	// kvSep separates the name and value of each line of namedargs input.
+	kvSep = "="
+	// rawValues controls whether namedargs values are written as Go
+	// expressions rather than quoted strings.
+	rawValues = false
+)
+
+// writeNamedArgs writes each NAME=VALUE line of b as a sql.Named argument in a
+// []sql.NamedArg. Names and values are trimmed of surrounding whitespace, and
+// values are quoted unless -raw-values is set. Blank lines are skipped. Lines
+// without a separator or with an invalid name are reported by line number.
+func writeNamedArgs(buf *bytes.Buffer, b []byte, depth int) {
+	var args []string
+	for i, line := range splitLines(b) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		sep := strings.Index(line, kvSep)
+		if sep == -1 {
+			fatalf("namedargs: line %d: missing separator %q", i+1, kvSep)
+		}
+		name := strings.TrimSpace(line[:sep])
+		value := strings.TrimSpace(line[sep+len(kvSep):])
+
+		// sql.Named panics if a name does not begin with a letter.
+		if r, _ := utf8.DecodeRuneInString(name); !unicode.IsLetter(r) {
+			fatalf("namedargs: line %d: name %q must begin with a letter", i+1, name)
+		}
+
+		if !rawValues {
+			value = strconv.Quote(value)
+		} else if value == "" {
+			fatalf("namedargs: line %d: value of %q is empty", i+1, name)
+		}
+		args = append(args, "sql.Named("+strconv.Quote(name)+", "+value+")")
+	}
+
+	buf.WriteString("[]sql.NamedArg{")
+	if len(args) == 0 {
+		buf.WriteByte('}')
+		return
+	}
+	for _, arg := range args {
+		newline(buf, depth+1)
+		buf.WriteString(arg + ",")
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
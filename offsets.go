@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// writeOffsets writes a const block declaring the offset of each field of the
+// record descriptor given by -fmt or, if -fmt is not set, by b. Each offset is
+// named Offset followed by its field's exported name, and the size of the
+// whole record is declared as SizeTotal. No data is written: the constants
+// describe the layout of a fixed-size binary format, so every field must have
+// a size.
+func writeOffsets(buf *bytes.Buffer, b []byte, depth int) {
+	if framing() > 0 {
+		fatalf("offsets mode writes a declaration and cannot be used with -loader, -var, -const, -once, -mapvar, or -unused-ok")
+	}
+	desc := recordFmt
+	if desc == "" {
+		desc = strings.TrimSpace(string(b))
+	}
+	fields, err := parseRecordFormat(desc)
+	if err != nil {
+		if swapped := swapRecordFormat(desc); swapped != "" {
+			fatalf("offsets: invalid record format: %v (fields are SIZE[TYPE]:NAME; did you mean %q?)", err, swapped)
+		}
+		fatalf("offsets: invalid record format: %v", err)
+	}
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		if f.size == -1 {
+			fatalf("offsets: field %d (%s): size must be fixed, not *", i+1, f.name)
+		}
+		names[i] = f.name
+	}
+	names = fieldNames(names)
+
+	width := len("SizeTotal")
+	for i, name := range names {
+		names[i] = "Offset" + name
+		if n := utf8.RuneCountInString(names[i]); n > width {
+			width = n
+		}
+	}
+
+	buf.WriteString("const (")
+	offset := 0
+	for i, f := range fields {
+		newline(buf, depth+1)
+		buf.WriteString(names[i] + strings.Repeat(" ", width-utf8.RuneCountInString(names[i])) + " = " + strconv.Itoa(offset))
+		offset += f.size
+	}
+	newline(buf, depth+1)
+	buf.WriteString("SizeTotal" + strings.Repeat(" ", width-len("SizeTotal")) + " = " + strconv.Itoa(offset))
+	newline(buf, depth)
+	buf.WriteByte(')')
+}
+
+// swapRecordFormat returns desc with the name and size of each field swapped,
+// if that makes it a valid record descriptor, or the empty string if not. It
+// is used to suggest a descriptor given as NAME:SIZE fields.
+func swapRecordFormat(desc string) string {
+	specs := strings.Split(desc, ",")
+	for i, spec := range specs {
+		colon := strings.IndexByte(spec, ':')
+		if colon == -1 {
+			return ""
+		}
+		specs[i] = spec[colon+1:] + ":" + spec[:colon]
+	}
+	swapped := strings.Join(specs, ",")
+	if _, err := parseRecordFormat(swapped); err != nil {
+		return ""
+	}
+	return swapped
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOffsetsSwappedFormat(t *testing.T) {
+	defer func(prev string) { recordFmt = prev }(recordFmt)
+
+	cases := []struct {
+		desc, hint string
+	}{
+		{"magic:4,version:2", `did you mean "4:magic,2:version"?`},
+		{"magic:4u,version:2l", `did you mean "4u:magic,2l:version"?`},
+		{"magic:4,version", ""},
+		{"magic:x,version:2", ""},
+	}
+	for _, c := range cases {
+		recordFmt = c.desc
+		msg := catchFatal(func() { writeString(nil, "offsets") })
+		if msg == "" {
+			t.Errorf("-fmt %s: no error", c.desc)
+		} else if c.hint == "" && strings.Contains(msg, "did you mean") {
+			t.Errorf("-fmt %s: error %q; want no suggestion", c.desc, msg)
+		} else if !strings.Contains(msg, c.hint) {
+			t.Errorf("-fmt %s: error %q; want it to contain %q", c.desc, msg, c.hint)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"encoding/pem"
+)
+
+// PEM flags.
+var (
+	// pemType is the PEM block type written by the pem mode and, if set,
+	// required of input decoded by -from-pem.
+	pemType = ""
+	// fromPEM controls whether input is decoded from a PEM block before it
+	// is written.
+	fromPEM = false
+)
+
+// writePEM writes b encoded as a PEM block of type -pem-type. The block is
+// written as a backquoted string, since PEM text is always backquotable.
+func writePEM(buf *bytes.Buffer, b []byte) {
+	if pemType == "" {
+		fatalf("pem mode requires a -pem-type")
+	}
+	buf.WriteByte('`')
+	buf.Write(pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: b}))
+	buf.WriteByte('`')
+}
+
+// decodePEM returns the contents of the PEM block in b. If b does not contain
+// exactly one PEM block, or -pem-type is set and differs from the block's type,
+// decodePEM exits with an error.
+func decodePEM(b []byte) []byte {
+	block, rest := pem.Decode(b)
+	if block == nil {
+		fatalf("unable to decode PEM: no PEM block found")
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		fatalf("unable to decode PEM: input contains more than one PEM block")
+	}
+	if pemType != "" && block.Type != pemType {
+		fatalf("unable to decode PEM: block type is %q, expected %q", block.Type, pemType)
+	}
+	return block.Bytes
+}
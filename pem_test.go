@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPEMRoundTrip(t *testing.T) {
+	defer func(typ string) { pemType = typ }(pemType)
+	pemType = "TEST DATA"
+
+	for _, in := range []string{"", "string", "\x00\xff binary \n data", strings.Repeat("long ", 40)} {
+		lit := writeString([]byte(in), "pem")
+		s, err := strconv.Unquote(lit)
+		if err != nil {
+			t.Fatalf("pem mode wrote %s for %q, which is not a string literal: %v", lit, in, err)
+		}
+		if got := decodePEM([]byte(s)); !bytes.Equal(got, []byte(in)) {
+			t.Errorf("decodePEM(pem(%q)) = %q", in, got)
+		}
+	}
+}
+
+func TestDecodePEMErrors(t *testing.T) {
+	defer func(typ string) { pemType = typ }(pemType)
+	pemType = "CERTIFICATE"
+	block := strings.Trim(writeString([]byte("data"), "pem"), "`")
+
+	cases := []struct {
+		name, typ, in, want string
+	}{
+		{"wrong type", "PRIVATE KEY", block, `block type is "CERTIFICATE", expected "PRIVATE KEY"`},
+		{"multiple blocks", "", block + block, "more than one PEM block"},
+		{"no block", "", "not pem", "no PEM block found"},
+	}
+	for _, c := range cases {
+		pemType = c.typ
+		msg := catchFatal(func() { decodePEM([]byte(c.in)) })
+		if !strings.Contains(msg, c.want) {
+			t.Errorf("%s: decodePEM error = %q; want it to contain %q", c.name, msg, c.want)
+		}
+	}
+}
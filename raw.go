@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// joinRaw controls whether multiple inputs are written as a single raw string
+// of the inputs joined by the separator, when possible.
+var joinRaw = false
+
+// canRawString returns whether b can be written as a multi-line raw string
+// literal with its contents unchanged. Unlike strconv.CanBackquote, newlines
+// are allowed. Carriage returns are not, since they are discarded from raw
+// strings, and neither are byte-order marks, which the compiler rejects.
+func canRawString(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		switch {
+		case r == '\t' || r == '\n':
+		case r == '`' || r == '\uFEFF' || r < ' ' || r == 0x7f:
+			return false
+		}
+	}
+	return true
+}
+
+// joinedRawMode is the mode in which inputs joined by -join-raw are written:
+// a raw string of the joined bytes as-is, which canRawString must allow. It is
+// not a valid MODE argument.
+const joinedRawMode = "`"
+
+// joinRawInputs returns inputs joined by sep as a single input, to be written
+// in joinedRawMode, and true if the result can be written as a raw string.
+// Otherwise, it returns inputs unchanged and false.
+func joinRawInputs(inputs [][]byte, sep string) ([][]byte, bool) {
+	joined := bytes.Join(inputs, []byte(sep))
+	if !canRawString(joined) {
+		return inputs, false
+	}
+	return [][]byte{joined}, true
+}
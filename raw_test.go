@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestJoinRawFraming(t *testing.T) {
+	defer func(join, unused, note bool) { joinRaw, unusedOK, noteSize = join, unused, note }(joinRaw, unusedOK, noteSize)
+	joinRaw = true
+
+	if got, want := renderString("q", "foo", "bar"), "`foo\nbar`"; got != want {
+		t.Errorf("-join-raw = %s; want %s", got, want)
+	}
+	if got, want := renderString("q", "a`", "b"), "\"a`\"\n\"b\""; got != want {
+		t.Errorf("-join-raw of inputs with a backquote = %s; want %s", got, want)
+	}
+
+	unusedOK, noteSize = true, true
+	if got, want := renderString("q", "foo", "bar"), "var _ = `foo\nbar` // 7 bytes"; got != want {
+		t.Errorf("-join-raw -unused-ok -note-size = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+// Reader flags, wrapping []byte and string expressions in a testing/iotest
+// reader.
+var (
+	// timeoutReader controls whether expressions are wrapped in an
+	// iotest.TimeoutReader.
+	timeoutReader = false
+	// errReader controls whether expressions are wrapped in an
+	// iotest.DataErrReader.
+	errReader = false
+)
+
+// iotestReader returns the testing/iotest function wrapping expressions, as
+// selected by -timeout-reader or -err-reader, or an empty string if neither is
+// set.
+func iotestReader() string {
+	switch {
+	case timeoutReader:
+		return "iotest.TimeoutReader"
+	case errReader:
+		return "iotest.DataErrReader"
+	}
+	return ""
+}
+
+// readerFlag returns the name of the flag selecting iotestReader.
+func readerFlag() string {
+	if timeoutReader {
+		return "-timeout-reader"
+	}
+	return "-err-reader"
+}
+
+// newReader returns the function creating a reader of the expression written by
+// mode for b: bytes.NewReader for a []byte, or strings.NewReader for a string.
+func newReader(mode string, b []byte) string {
+	switch typ := exprType(mode, b); typ {
+	case "[]byte":
+		return "bytes.NewReader"
+	case "string":
+		return "strings.NewReader"
+	case "":
+		fatalf("%s is not supported by mode %q", readerFlag(), mode)
+	default:
+		fatalf("%s cannot read a %s", readerFlag(), typ)
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReaderFlags(t *testing.T) {
+	defer func(timeout, data bool, mv string) { timeoutReader, errReader, mapVar = timeout, data, mv }(timeoutReader, errReader, mapVar)
+
+	timeoutReader = true
+	if got, want := renderString("b", "hi"), "iotest.TimeoutReader(bytes.NewReader([]byte{0x68, 0x69}))"; got != want {
+		t.Errorf("-timeout-reader = %s; want %s", got, want)
+	}
+	timeoutReader, errReader = false, true
+	if got, want := renderString("q", "hi"), `iotest.DataErrReader(strings.NewReader("hi"))`; got != want {
+		t.Errorf("-err-reader = %s; want %s", got, want)
+	}
+
+	defer func(files stringList) { inputFiles = files }(inputFiles)
+	mapVar, inputFiles = "m", stringList{"a.txt"}
+	msg := catchFatal(checkFlags)
+	if !strings.Contains(msg, "-err-reader cannot be used with") {
+		t.Errorf("checkFlags() with -err-reader and -mapvar = %q; want an error", msg)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// recordField is a single field of a record descriptor, as given by -fmt.
+type recordField struct {
+	name string
+	size int  // Size in bytes, or -1 for the rest of the input.
+	kind byte // One of 's', 'u', 'l', or 'x'.
+}
+
+// parseRecordFormat parses a record descriptor of the form
+// SIZE[TYPE]:NAME[,SIZE[TYPE]:NAME...]. SIZE is a number of bytes or * for
+// the rest of the input, which is only permitted for the last field. TYPE is
+// one of:
+//
+//	x - Raw bytes (default)
+//	s - String
+//	u - Big-endian unsigned integer (at most 8 bytes)
+//	l - Little-endian unsigned integer (at most 8 bytes)
+func parseRecordFormat(desc string) ([]recordField, error) {
+	if desc == "" {
+		return nil, errors.New("record format is empty")
+	}
+	specs := strings.Split(desc, ",")
+	fields := make([]recordField, 0, len(specs))
+	for i, spec := range specs {
+		colon := strings.IndexByte(spec, ':')
+		if colon == -1 {
+			return nil, fmt.Errorf("field %d (%q): expected SIZE[TYPE]:NAME", i+1, spec)
+		}
+		size, name := spec[:colon], spec[colon+1:]
+		if name == "" {
+			return nil, fmt.Errorf("field %d (%q): name is empty", i+1, spec)
+		}
+
+		f := recordField{name: name, kind: 'x'}
+		if n := len(size); n > 0 && strings.IndexByte("xsul", size[n-1]) != -1 {
+			f.kind, size = size[n-1], size[:n-1]
+		}
+
+		if size == "*" {
+			if i != len(specs)-1 {
+				return nil, fmt.Errorf("field %d (%q): * is only permitted for the last field", i+1, spec)
+			}
+			f.size = -1
+		} else if n, err := strconv.Atoi(size); err != nil || n < 1 {
+			return nil, fmt.Errorf("field %d (%q): invalid size %q", i+1, spec, size)
+		} else {
+			f.size = n
+		}
+
+		if (f.kind == 'u' || f.kind == 'l') && (f.size < 1 || f.size > 8) {
+			return nil, fmt.Errorf("field %d (%q): integer fields must be 1 to 8 bytes", i+1, spec)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// describe returns a comment describing the value of the field f, whose
+// contents are p.
+func (f recordField) describe(p []byte) string {
+	switch f.kind {
+	case 's':
+		return f.name + ": " + strconv.Quote(string(p))
+	case 'u', 'l':
+		var v uint64
+		for i := range p {
+			c := p[i]
+			if f.kind == 'l' {
+				c = p[len(p)-1-i]
+			}
+			v = v<<8 | uint64(c)
+		}
+		return f.name + ": " + strconv.FormatUint(v, 10)
+	}
+	return f.name
+}
+
+// recordLineBytes is the maximum number of bytes written on a single line of
+// a record field.
+const recordLineBytes = 16
+
+// writeRecords writes b as a []byte literal, grouped into records described by
+// fields. Each field is written on its own line(s) with a comment naming it
+// and, for string and integer fields, its value. The descriptor is repeated
+// until b is exhausted; a final record that does not fill the descriptor is
+// written with its short and missing fields noted.
+func writeRecords(buf *bytes.Buffer, b []byte, fields []recordField, depth int) {
+	if len(b) == 0 {
+		buf.WriteString("[]byte{}")
+		return
+	}
+
+	var lines []byteLine
+	for rec := 0; len(b) > 0; rec++ {
+		lines = append(lines, byteLine{nil, "record " + strconv.Itoa(rec)})
+		for i, f := range fields {
+			if len(b) == 0 {
+				names := make([]string, 0, len(fields)-i)
+				for _, m := range fields[i:] {
+					names = append(names, m.name)
+				}
+				lines = append(lines, byteLine{nil, "missing: " + strings.Join(names, ", ")})
+				break
+			}
+
+			n := f.size
+			if n == -1 || n > len(b) {
+				n = len(b)
+			}
+			p := b[:n]
+			b = b[n:]
+
+			comment := f.describe(p)
+			if f.size != -1 && n < f.size {
+				comment = fmt.Sprintf("%s (short: %d of %d bytes)", f.name, n, f.size)
+			}
+			for len(p) > recordLineBytes {
+				lines = append(lines, byteLine{p[:recordLineBytes], comment})
+				p, comment = p[recordLineBytes:], ""
+			}
+			lines = append(lines, byteLine{p, comment})
+		}
+	}
+	writeByteLines(buf, lines, depth)
+}
+
+// byteLine is a line of a []byte literal: the bytes p and, if comment is not
+// empty, a line comment following them.
+type byteLine struct {
+	p       []byte
+	comment string
+}
+
+// writeByteLines writes a []byte literal of lines, each written on a line of
+// its own. A line holding no bytes is written as only its comment. Line
+// comments are aligned as gofmt aligns them: across runs of commented lines of
+// a single byte, along with the commented line following each run.
+func writeByteLines(buf *bytes.Buffer, lines []byteLine, depth int) {
+	aligned := func(l byteLine) bool {
+		return len(l.p) > 0 && l.comment != ""
+	}
+
+	buf.WriteString("[]byte{")
+	for i := 0; i < len(lines); {
+		j := i + 1
+		if aligned(lines[i]) {
+			for j < len(lines) && aligned(lines[j]) && len(lines[j-1].p) == 1 {
+				j++
+			}
+		}
+
+		// Each byte is written as "0xNN," and separated by a space.
+		width := 0
+		for _, l := range lines[i:j] {
+			if n := 6*len(l.p) - 1; n > width {
+				width = n
+			}
+		}
+		for _, l := range lines[i:j] {
+			newline(buf, depth+1)
+			for k, c := range l.p {
+				if k > 0 {
+					buf.WriteByte(' ')
+				}
+				fmt.Fprintf(buf, "0x%02x,", c)
+			}
+			if l.comment != "" {
+				if len(l.p) > 0 {
+					buf.WriteString(strings.Repeat(" ", width-(6*len(l.p)-1)+1))
+				}
+				buf.WriteString("// " + l.comment)
+			}
+		}
+		i = j
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
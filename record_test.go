@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRecordFormat(t *testing.T) {
+	fields, err := parseRecordFormat("2s:magic,1u:version,8l:n,3:raw,*x:rest")
+	if err != nil {
+		t.Fatalf("parseRecordFormat: %v", err)
+	}
+	want := []recordField{
+		{"magic", 2, 's'},
+		{"version", 1, 'u'},
+		{"n", 8, 'l'},
+		{"raw", 3, 'x'},
+		{"rest", -1, 'x'},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("parseRecordFormat = %+v; want %+v", fields, want)
+	}
+
+	errs := []struct {
+		desc, err string
+	}{
+		{"", "record format is empty"},
+		{"2s", `field 1 ("2s"): expected SIZE[TYPE]:NAME`},
+		{"1:a,2s:", `field 2 ("2s:"): name is empty`},
+		{"*:a,1:b", `field 1 ("*:a"): * is only permitted for the last field`},
+		{"0:a", `field 1 ("0:a"): invalid size "0"`},
+		{"-1:a", `field 1 ("-1:a"): invalid size "-1"`},
+		{"s:a", `field 1 ("s:a"): invalid size ""`},
+		{"2q:a", `field 1 ("2q:a"): invalid size "2q"`},
+		{"9u:a", `field 1 ("9u:a"): integer fields must be 1 to 8 bytes`},
+		{"9l:a", `field 1 ("9l:a"): integer fields must be 1 to 8 bytes`},
+		{"*u:a", `field 1 ("*u:a"): integer fields must be 1 to 8 bytes`},
+	}
+	for _, c := range errs {
+		if _, err := parseRecordFormat(c.desc); err == nil || err.Error() != c.err {
+			t.Errorf("parseRecordFormat(%q) error = %v; want %s", c.desc, err, c.err)
+		}
+	}
+}
+
+// recordsString returns b written by writeRecords with the fields of desc.
+func recordsString(t *testing.T, b []byte, desc string) string {
+	fields, err := parseRecordFormat(desc)
+	if err != nil {
+		t.Fatalf("parseRecordFormat(%q): %v", desc, err)
+	}
+	var buf bytes.Buffer
+	writeRecords(&buf, b, fields, 0)
+	return buf.String()
+}
+
+func TestWriteRecords(t *testing.T) {
+	cases := []struct {
+		desc, in, want string
+	}{
+		{"2s:magic,1u:version,*:payload", "string", "[]byte{\n" +
+			"\t// record 0\n" +
+			"\t0x73, 0x74, // magic: \"st\"\n" +
+			"\t0x72,             // version: 114\n" +
+			"\t0x69, 0x6e, 0x67, // payload\n" +
+			"}"},
+		{"1u:a,2l:b", "\x01\x02\x03\x04\x05", "[]byte{\n" +
+			"\t// record 0\n" +
+			"\t0x01,       // a: 1\n" +
+			"\t0x02, 0x03, // b: 770\n" +
+			"\t// record 1\n" +
+			"\t0x04, // a: 4\n" +
+			"\t0x05, // b (short: 1 of 2 bytes)\n" +
+			"}"},
+		{"1:a,1s:b,1:c", "xyzw", "[]byte{\n" +
+			"\t// record 0\n" +
+			"\t0x78, // a\n" +
+			"\t0x79, // b: \"y\"\n" +
+			"\t0x7a, // c\n" +
+			"\t// record 1\n" +
+			"\t0x77, // a\n" +
+			"\t// missing: b, c\n" +
+			"}"},
+		{"*:rest", "0123456789abcdefg", "[]byte{\n" +
+			"\t// record 0\n" +
+			"\t0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, // rest\n" +
+			"\t0x67,\n" +
+			"}"},
+		{"1:a", "", "[]byte{}"},
+	}
+	for _, c := range cases {
+		if got := recordsString(t, []byte(c.in), c.desc); got != c.want {
+			t.Errorf("writeRecords(%q, %s) =\n%s\nwant\n%s", c.in, c.desc, got, c.want)
+		}
+	}
+}
+
+func TestWriteRecordsGofmt(t *testing.T) {
+	in := []byte(strings.Repeat("abcdefghijklmnopqrstuvwxyz", 3))
+	descs := []string{
+		"1u:a,1u:b,3s:c",
+		"1:a,2:b,1:c,1:d,4:e",
+		"4s:magic,1u:v,2l:len,*:rest",
+		"1:a,17:b,1:c,1:d",
+		"5:a,1:b",
+	}
+	for _, desc := range descs {
+		src := "package p\n\nvar d = " + recordsString(t, in, desc) + "\n"
+		if got, err := format.Source([]byte(src)); err != nil {
+			t.Errorf("-fmt %s: %v", desc, err)
+		} else if string(got) != src {
+			t.Errorf("-fmt %s: output is not gofmt-formatted:\n%s\ngofmt:\n%s", desc, src, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// regressTarget is the function called with the input by the test written by
+// the regress mode.
+var regressTarget = ""
+
+// isTarget returns whether s is a function name that may be given to -target:
+// an identifier, optionally qualified by a package or receiver name.
+func isTarget(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if !isIdentifier(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeRegress writes a test function calling the function named by -target
+// with b, failing if it panics, to check in an input found by fuzzing or
+// testing/quick as a regression test. The test is named by the FNV-1a hash of
+// b, so that tests of different inputs do not collide.
+func writeRegress(buf *bytes.Buffer, b []byte, depth int) {
+	if regressTarget == "" {
+		fatalf("regress mode requires a -target")
+	} else if framing() > 0 {
+		fatalf("regress mode writes a declaration and cannot be used with -loader, -var, -const, -once, -mapvar, or -unused-ok")
+	}
+
+	h := fnv.New32a()
+	h.Write(b)
+	fmt.Fprintf(buf, "func TestRegression%08x(t *testing.T) {", h.Sum32())
+	newline(buf, depth+1)
+	buf.WriteString("input := ")
+	write(buf, b, "0b", depth+1)
+	writeSource(buf, "\n"+
+		"defer func() {\n"+
+		"\tif r := recover(); r != nil {\n"+
+		"\t\tt.Fatalf(\""+regressTarget+" panicked on input %q: %v\", input, r)\n"+
+		"\t}\n"+
+		"}()\n"+
+		regressTarget+"(input)", depth+1)
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
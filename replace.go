@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Replacement flags.
+var (
+	// replaceFile, if set, is a Go source file in which the value of the
+	// declaration named by -marker is replaced with the output.
+	replaceFile = ""
+	// marker is the name of the var or const declaration whose value is
+	// replaced in -replace-in.
+	marker = ""
+	// diffOnly controls whether -replace-in writes a unified diff of its
+	// change to standard output instead of modifying the file.
+	diffOnly = false
+)
+
+// diffContext is the number of lines of context around a change in a diff
+// written by -diff-only.
+const diffContext = 3
+
+// findValue returns the start and end offsets, in src, of the value assigned
+// to the package-level var or const named name. depth is the indentation depth
+// of the declaration: 1 for declarations in a parenthesized group, otherwise
+// 0.
+func findValue(path string, src []byte, name string) (start, end, depth int, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, ident := range vs.Names {
+				if ident.Name != name {
+					continue
+				}
+				if i >= len(vs.Values) {
+					return 0, 0, 0, fmt.Errorf("declaration of %s has no value", name)
+				}
+				if gen.Lparen.IsValid() {
+					depth = 1
+				}
+				v := vs.Values[i]
+				return fset.Position(v.Pos()).Offset, fset.Position(v.End()).Offset, depth, nil
+			}
+		}
+	}
+	return 0, 0, 0, fmt.Errorf("marker %s not found", name)
+}
+
+// replaceValue replaces the value of the declaration named by -marker in the
+// file given by -replace-in with b written in the given mode. If -diff-only is
+// set, the file is left unmodified and a unified diff of the change is written
+// to out instead.
+func replaceValue(out *bytes.Buffer, b []byte, mode string) error {
+	if marker == "" {
+		return errors.New("-replace-in requires a -marker")
+	}
+
+	fi, err := os.Stat(replaceFile)
+	if err != nil {
+		return err
+	}
+	src, err := ioutil.ReadFile(replaceFile)
+	if err != nil {
+		return err
+	}
+
+	start, end, depth, err := findValue(replaceFile, src, marker)
+	if err != nil {
+		return fmt.Errorf("%s: %v", replaceFile, err)
+	}
+
+	var dst bytes.Buffer
+	dst.Write(src[:start])
+	if comment := writeExpr(&dst, b, mode, depth); comment != "" {
+		dst.WriteString(lineComment(comment))
+	}
+	newEnd := dst.Len()
+	dst.Write(src[end:])
+
+	if bytes.Equal(src, dst.Bytes()) {
+		return nil
+	} else if diffOnly {
+		writeDiff(out, replaceFile, src, dst.Bytes(), start, end, newEnd)
+		return nil
+	}
+	return ioutil.WriteFile(replaceFile, dst.Bytes(), fi.Mode().Perm())
+}
+
+// writeDiff writes a unified diff of the file at path, changed from old to new
+// by replacing old[start:oldEnd] with new[start:newEnd]. Since only a single
+// range of the file changes, the diff contains a single hunk.
+func writeDiff(out *bytes.Buffer, path string, old, new []byte, start, oldEnd, newEnd int) {
+	oldLines := strings.SplitAfter(string(old), "\n")
+	newLines := strings.SplitAfter(string(new), "\n")
+	if oldLines[len(oldLines)-1] == "" {
+		oldLines = oldLines[:len(oldLines)-1]
+	}
+	if newLines[len(newLines)-1] == "" {
+		newLines = newLines[:len(newLines)-1]
+	}
+
+	// first is the first changed line, and oldLast and newLast the last
+	// changed lines of old and new, respectively.
+	first := bytes.Count(old[:start], []byte("\n"))
+	oldLast := bytes.Count(old[:oldEnd], []byte("\n"))
+	newLast := bytes.Count(new[:newEnd], []byte("\n"))
+
+	lo := first - diffContext
+	if lo < 0 {
+		lo = 0
+	}
+	oldHi := oldLast + 1 + diffContext
+	if oldHi > len(oldLines) {
+		oldHi = len(oldLines)
+	}
+	newHi := newLast + 1 + (oldHi - oldLast - 1)
+
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", path, path)
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(lo, oldHi), hunkRange(lo, newHi))
+	line := func(prefix, text string) {
+		out.WriteString(prefix + text)
+		if !strings.HasSuffix(text, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	for _, l := range oldLines[lo:first] {
+		line(" ", l)
+	}
+	for _, l := range oldLines[first : oldLast+1] {
+		line("-", l)
+	}
+	for _, l := range newLines[first : newLast+1] {
+		line("+", l)
+	}
+	for _, l := range oldLines[oldLast+1 : oldHi] {
+		line(" ", l)
+	}
+}
+
+// hunkRange returns the range of lines [lo, hi) in the form used by unified
+// diff hunk headers.
+func hunkRange(lo, hi int) string {
+	n := hi - lo
+	if n == 0 {
+		return fmt.Sprintf("%d,0", lo)
+	} else if n == 1 {
+		return fmt.Sprintf("%d", lo+1)
+	}
+	return fmt.Sprintf("%d,%d", lo+1, n)
+}
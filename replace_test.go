@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// replaceSrc is a file in which to replace values. It has no trailing newline.
+const replaceSrc = "package p\n\nvar (\n\ta = 1\n\tb = \"x\"\n)\n\nconst c = 2"
+
+func TestFindValue(t *testing.T) {
+	cases := []struct {
+		name, value string
+		depth       int
+		err         string
+	}{
+		{name: "a", value: "1", depth: 1},
+		{name: "b", value: `"x"`, depth: 1},
+		{name: "c", value: "2", depth: 0},
+		{name: "d", err: "marker d not found"},
+	}
+	for _, c := range cases {
+		start, end, depth, err := findValue("p.go", []byte(replaceSrc), c.name)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("findValue(%s) error = %v; want %s", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("findValue(%s): %v", c.name, err)
+		} else if value := replaceSrc[start:end]; value != c.value || depth != c.depth {
+			t.Errorf("findValue(%s) = %s at depth %d; want %s at depth %d", c.name, value, depth, c.value, c.depth)
+		}
+	}
+
+	if _, _, _, err := findValue("p.go", []byte("package p\n\nvar e int\n"), "e"); err == nil {
+		t.Error("findValue(e) of a declaration without a value succeeded; want error")
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	cases := []struct {
+		lo, hi int
+		want   string
+	}{
+		{0, 0, "0,0"},
+		{4, 4, "4,0"},
+		{0, 1, "1"},
+		{4, 5, "5"},
+		{0, 7, "1,7"},
+		{4, 8, "5,4"},
+	}
+	for _, c := range cases {
+		if got := hunkRange(c.lo, c.hi); got != c.want {
+			t.Errorf("hunkRange(%d, %d) = %s; want %s", c.lo, c.hi, got, c.want)
+		}
+	}
+}
+
+func TestReplaceValue(t *testing.T) {
+	defer func(file, mark string, diff bool) { replaceFile, marker, diffOnly = file, mark, diff }(replaceFile, marker, diffOnly)
+
+	dir, err := ioutil.TempDir("", "goquote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	replaceFile = filepath.Join(dir, "p.go")
+	if err := ioutil.WriteFile(replaceFile, []byte(replaceSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		marker, in, mode, want string
+	}{
+		{"b", "a\nb", "ql", "" +
+			"--- " + replaceFile + "\n" +
+			"+++ " + replaceFile + "\n" +
+			"@@ -2,7 +2,8 @@\n" +
+			" \n" +
+			" var (\n" +
+			" \ta = 1\n" +
+			"-\tb = \"x\"\n" +
+			"+\tb = \"a\\n\" +\n" +
+			"+\t\t\"b\"\n" +
+			" )\n" +
+			" \n" +
+			" const c = 2\n" +
+			"\\ No newline at end of file\n"},
+		{"c", "hi", "q", "" +
+			"--- " + replaceFile + "\n" +
+			"+++ " + replaceFile + "\n" +
+			"@@ -5,4 +5,4 @@\n" +
+			" \tb = \"x\"\n" +
+			" )\n" +
+			" \n" +
+			"-const c = 2\n" +
+			"\\ No newline at end of file\n" +
+			"+const c = \"hi\"\n" +
+			"\\ No newline at end of file\n"},
+		{"a", "\x01", "bs", "" +
+			"--- " + replaceFile + "\n" +
+			"+++ " + replaceFile + "\n" +
+			"@@ -1,7 +1,7 @@\n" +
+			" package p\n" +
+			" \n" +
+			" var (\n" +
+			"-\ta = 1\n" +
+			"+\ta = []byte(\"\\x01\")\n" +
+			" \tb = \"x\"\n" +
+			" )\n" +
+			" \n"},
+		{"b", "x", "q", ""},
+	}
+
+	diffOnly = true
+	for _, c := range cases {
+		marker = c.marker
+		var out bytes.Buffer
+		if err := replaceValue(&out, []byte(c.in), c.mode); err != nil {
+			t.Errorf("-diff-only -marker %s: %v", c.marker, err)
+		} else if got := out.String(); got != c.want {
+			t.Errorf("-diff-only -marker %s =\n%s\nwant\n%s", c.marker, got, c.want)
+		}
+	}
+	if b, _ := ioutil.ReadFile(replaceFile); string(b) != replaceSrc {
+		t.Errorf("-diff-only modified the file:\n%s", b)
+	}
+
+	diffOnly, marker = false, "c"
+	var out bytes.Buffer
+	if err := replaceValue(&out, []byte("hi"), "q"); err != nil {
+		t.Fatalf("-marker c: %v", err)
+	}
+	want := replaceSrc[:len(replaceSrc)-1] + `"hi"`
+	if b, _ := ioutil.ReadFile(replaceFile); string(b) != want || out.Len() != 0 {
+		t.Errorf("-marker c wrote %q to the file and %q to output; want %q and nothing", b, out.String(), want)
+	}
+
+	marker = "d"
+	if err := replaceValue(&out, []byte("hi"), "q"); err == nil {
+		t.Error("-marker d succeeded; want error")
+	}
+}
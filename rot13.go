@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// caesarShift is the number of places the rot13 mode shifts letters forward in
+// the alphabet.
+var caesarShift = 13
+
+// caesar returns b with each ASCII letter shifted n places forward in the
+// alphabet, wrapping from z to a, and keeping its case. Other bytes, including
+// non-ASCII letters, are unchanged. A negative n shifts letters backward.
+func caesar(b []byte, n int) []byte {
+	n = (n%26 + 26) % 26
+	p := make([]byte, len(b))
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z':
+			c = 'a' + (c-'a'+byte(n))%26
+		case c >= 'A' && c <= 'Z':
+			c = 'A' + (c-'A'+byte(n))%26
+		}
+		p[i] = c
+	}
+	return p
+}
+
+// caesarNote returns the annotation describing how to decode the output of
+// the rot13 mode.
+func caesarNote() string {
+	n := (caesarShift%26 + 26) % 26
+	if n == 13 {
+		return "rot13"
+	}
+	places := strconv.Itoa(26-n) + " places"
+	if n == 25 {
+		places = "1 place"
+	}
+	return "caesar " + strconv.Itoa(n) + ": decode by shifting letters " + places
+}
+
+// writeROT13 writes b, with its letters shifted by -caesar, as a quoted string.
+// This only keeps the text from being read at a glance or found by strings(1);
+// it is not encryption.
+func writeROT13(buf *bytes.Buffer, b []byte, depth int) {
+	write(buf, caesar(b, caesarShift), "q", depth)
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestCaesar(t *testing.T) {
+	cases := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"Hello, World!", 13, "Uryyb, Jbeyq!"},
+		{"xyz XYZ", 3, "abc ABC"},
+		{"abc", -3, "xyz"},
+		{"abc", 29, "def"},
+		{"0123456789 !@#$%^&*()[]{}<>`~_-+=|\\/?.,;:'\"\t\n\x00\xff", 13, "0123456789 !@#$%^&*()[]{}<>`~_-+=|\\/?.,;:'\"\t\n\x00\xff"},
+		{"été ß 日本", 13, "égé ß 日本"},
+	}
+	for _, c := range cases {
+		if got := string(caesar([]byte(c.in), c.n)); got != c.want {
+			t.Errorf("caesar(%q, %d) = %q; want %q", c.in, c.n, got, c.want)
+		}
+	}
+}
+
+func TestROT13SelfInverse(t *testing.T) {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	if got := caesar(caesar(b, 13), 13); string(got) != string(b) {
+		t.Errorf("caesar(caesar(b, 13), 13) = %q; want %q", got, b)
+	}
+}
+
+func TestCaesarNote(t *testing.T) {
+	defer func(prev int) { caesarShift = prev }(caesarShift)
+	for n, want := range map[int]string{
+		13:  "rot13",
+		3:   "caesar 3: decode by shifting letters 23 places",
+		-1:  "caesar 25: decode by shifting letters 1 place",
+		-13: "rot13",
+	} {
+		caesarShift = n
+		if got := caesarNote(); got != want {
+			t.Errorf("caesarNote() with -caesar %d = %q; want %q", n, got, want)
+		}
+	}
+}
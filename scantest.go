@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// scanOn is the split function used by the scantest mode: lines, words, runes,
+// or bytes for the bufio split function of the same name, or any other string
+// to split on it as a delimiter.
+var scanOn = "lines"
+
+// scanDelim returns a bufio.SplitFunc that splits tokens on delim. It behaves
+// as bufio.ScanLines does for "\n", except that carriage returns are kept: a
+// final delimiter does not begin an empty token, but consecutive delimiters
+// enclose one.
+func scanDelim(delim []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, delim); i >= 0 {
+			return i + len(delim), data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// scanTokens returns the tokens of b split by -on, as read by a bufio.Scanner.
+func scanTokens(b []byte) []string {
+	split := scanDelim([]byte(scanOn))
+	switch scanOn {
+	case "lines":
+		split = bufio.ScanLines
+	case "words":
+		split = bufio.ScanWords
+	case "runes":
+		split = bufio.ScanRunes
+	case "bytes":
+		split = bufio.ScanBytes
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 4096), len(b)+1)
+	sc.Split(split)
+	tokens := []string{}
+	for sc.Scan() {
+		tokens = append(tokens, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		fatalf("scantest: %v", err)
+	}
+	return tokens
+}
+
+// writeScanTest writes b as a struct literal pairing it, as Input, with the
+// tokens a bufio.Scanner splitting on -on reads from it, as Tokens.
+func writeScanTest(buf *bytes.Buffer, b []byte, depth int) {
+	buf.WriteString("struct {")
+	newline(buf, depth+1)
+	buf.WriteString("Input  []byte")
+	newline(buf, depth+1)
+	buf.WriteString("Tokens []string")
+	newline(buf, depth)
+	buf.WriteString("}{")
+	newline(buf, depth+1)
+	buf.WriteString("Input:  ")
+	write(buf, b, "bs", depth+1)
+	buf.WriteByte(',')
+	newline(buf, depth+1)
+	buf.WriteString("Tokens: ")
+	writeStringSlice(buf, scanTokens(b))
+	buf.WriteByte(',')
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanDelimLines(t *testing.T) {
+	defer func(prev string) { scanOn = prev }(scanOn)
+
+	// scanDelim keeps carriage returns, which bufio.ScanLines drops, so
+	// none of these inputs contain one.
+	inputs := []string{
+		"",
+		"\n",
+		"\n\n",
+		"a",
+		"a\n",
+		"a\nb",
+		"a\nb\n",
+		"a\n\nb",
+		"a\n\n\nb\n\n",
+		"\na",
+	}
+	for _, in := range inputs {
+		scanOn = "lines"
+		want := scanTokens([]byte(in))
+		scanOn = "\n"
+		if got := scanTokens([]byte(in)); !reflect.DeepEqual(got, want) {
+			t.Errorf("scanTokens(%q) on newlines = %q; want %q as from lines", in, got, want)
+		}
+	}
+}
+
+func TestScanDelim(t *testing.T) {
+	defer func(prev string) { scanOn = prev }(scanOn)
+
+	cases := []struct {
+		on, in string
+		want   []string
+	}{
+		{"\n", "a\r\nb\r\n", []string{"a\r", "b\r"}},
+		{"::", "a::b", []string{"a", "b"}},
+		{"::", "a::b::", []string{"a", "b"}},
+		{"::", "a::::b", []string{"a", "", "b"}},
+		{"::", "a:b:::c", []string{"a:b", ":c"}},
+		{"::", "ab", []string{"ab"}},
+		{"::", "::", []string{""}},
+		{"::", "", []string{}},
+	}
+	for _, c := range cases {
+		scanOn = c.on
+		if got := scanTokens([]byte(c.in)); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("scanTokens(%q) on %q = %q; want %q", c.in, c.on, got, c.want)
+		}
+	}
+}
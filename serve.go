@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// serveAddr, if set, is the address on which goquote serves JSON-RPC 2.0
+// requests instead of reading its input: either host:port for TCP or
+// unix:PATH for a Unix socket.
+var serveAddr = ""
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// noServeFlags are the flags that cannot be set by a quote request, since they
+// either configure the server itself, apply only to standard input, modify
+// files, or run commands.
+var noServeFlags = map[string]bool{
+	"serve":       true,
+	"s":           true,
+	"c":           true,
+	"strip-bom":   true,
+	"replace-in":  true,
+	"marker":      true,
+	"diff-only":   true,
+	"f":           true,
+	"fmt-cmd":     true,
+	"split-files": true,
+	"o-prefix":    true,
+}
+
+type rpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  *string         `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// quoteParams are the parameters of the quote method. Flags are named as on
+// the command line, without a leading dash, and may be given as strings,
+// numbers, or booleans.
+type quoteParams struct {
+	Mode  string                 `json:"mode"`
+	Input string                 `json:"input"`
+	Flags map[string]interface{} `json:"flags"`
+}
+
+// quoteError is the panic value of fatalf while serving.
+type quoteError string
+
+// server holds the state shared by all connections to the server. Since modes
+// are configured through package-level flags, requests are handled one at a
+// time.
+type server struct {
+	mu       sync.Mutex
+	defaults map[string]string
+}
+
+// serve listens on addr and answers JSON-RPC 2.0 requests on each connection
+// until the listener fails. Requests and responses are JSON values, each
+// response written on its own line.
+func serve(addr string) error {
+	network := "tcp"
+	if strings.HasPrefix(addr, "unix:") {
+		network, addr = "unix", addr[len("unix:"):]
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	fatalf = func(format string, args ...interface{}) {
+		panic(quoteError(fmt.Sprintf(format, args...)))
+	}
+
+	s := &server{defaults: map[string]string{}}
+	flag.VisitAll(func(f *flag.Flag) {
+		s.defaults[f.Name] = f.Value.String()
+	})
+
+	log.Printf("Serving on %s", l.Addr())
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn answers the requests read from conn until it is closed or a
+// request cannot be parsed.
+func (s *server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return
+		} else if err != nil {
+			// The rest of the stream cannot be parsed reliably, so end
+			// the connection after reporting the error.
+			enc.Encode(errorResponse(nil, rpcParseError, "parse error: "+err.Error()))
+			return
+		}
+		if resp := s.handle(raw); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				log.Printf("Unable to write response to %s: %v", conn.RemoteAddr(), err)
+				return
+			}
+		}
+	}
+}
+
+// handle returns the response to the request raw, or nil if it is a
+// notification.
+func (s *server) handle(raw json.RawMessage) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, rpcInvalidRequest, "invalid request: "+err.Error())
+	}
+	if req.Version != "2.0" {
+		return errorResponse(req.ID, rpcInvalidRequest, `invalid request: jsonrpc must be "2.0"`)
+	}
+
+	var resp *rpcResponse
+	switch req.Method {
+	case "quote":
+		var p quoteParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			resp = errorResponse(req.ID, rpcInvalidParams, "invalid params: "+err.Error())
+			break
+		}
+		result, code, err := s.quote(p)
+		if err != nil {
+			resp = errorResponse(req.ID, code, err.Error())
+			break
+		}
+		resp = &rpcResponse{Version: "2.0", ID: req.ID, Result: &result}
+	default:
+		resp = errorResponse(req.ID, rpcMethodNotFound, fmt.Sprintf("method not found: %q", req.Method))
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	return resp
+}
+
+// quote returns the input of p written in its mode with its flags set. If the
+// request fails, it returns a JSON-RPC error code and the error.
+func (s *server) quote(p quoteParams) (result string, code int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.reset()
+	defer func() {
+		if v := recover(); v != nil {
+			if qe, ok := v.(quoteError); ok {
+				result, code, err = "", rpcInvalidParams, fmt.Errorf("%s", string(qe))
+				return
+			}
+			log.Printf("Panic while handling request: %v", v)
+			result, code, err = "", rpcInternalError, fmt.Errorf("internal error: %v", v)
+		}
+	}()
+
+	for name, v := range p.Flags {
+		if noServeFlags[name] {
+			return "", rpcInvalidParams, fmt.Errorf("flag -%s cannot be set by a request", name)
+		}
+		var value string
+		switch v := v.(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return "", rpcInvalidParams, fmt.Errorf("invalid value of flag -%s: must be a string, number, or boolean", name)
+		}
+		if err := flag.Set(name, value); err != nil {
+			return "", rpcInvalidParams, fmt.Errorf("invalid value of flag -%s: %v", name, err)
+		}
+	}
+	checkFlags()
+
+	inputs := [][]byte{[]byte(p.Input)}
+	if fromPEM {
+		inputs[0] = decodePEM(inputs[0])
+	}
+	inputs = collectElems(inputs, p.Mode)
+
+	var buf bytes.Buffer
+	render(&buf, inputs, p.Mode, "\n")
+	return buf.String(), 0, nil
+}
+
+// reset restores all flags to the values they had when the server started.
+// Flags that requests cannot set are skipped, since setting a repeatable flag
+// adds to its values.
+func (s *server) reset() {
+	for name, value := range s.defaults {
+		if !noServeFlags[name] {
+			flag.Set(name, value)
+		}
+	}
+	elems = nil
+}
+
+// errorResponse returns an error response to the request with the given id.
+func errorResponse(id json.RawMessage, code int, message string) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{
+		Version: "2.0",
+		ID:      id,
+		Error:   &rpcError{Code: code, Message: message},
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestServerHandle(t *testing.T) {
+	defer func(prev func(string, ...interface{})) { fatalf = prev }(fatalf)
+	fatalf = func(format string, args ...interface{}) {
+		panic(quoteError(fmt.Sprintf(format, args...)))
+	}
+
+	// Requests set flags through flag.CommandLine, so register the flags
+	// used below on a command line of their own, as main does.
+	defer func(prev *flag.FlagSet) { flag.CommandLine = prev }(flag.CommandLine)
+	defer func(name string, note bool) { varName, noteSize = name, note }(varName, noteSize)
+	flag.CommandLine = flag.NewFlagSet("goquote", flag.ContinueOnError)
+	sep := "\n"
+	flag.StringVar(&sep, "s", sep, "Separator")
+	flag.Var(&inputFiles, "f", "Input file")
+	flag.StringVar(&varName, "var", varName, "Variable name")
+	flag.BoolVar(&noteSize, "note-size", noteSize, "Note size")
+
+	s := &server{defaults: map[string]string{}}
+	flag.VisitAll(func(f *flag.Flag) {
+		s.defaults[f.Name] = f.Value.String()
+	})
+
+	cases := []struct {
+		name, req string
+		result    string
+		code      int
+	}{
+		{
+			name:   "valid",
+			req:    `{"jsonrpc": "2.0", "id": 1, "method": "quote", "params": {"mode": "q", "input": "hi", "flags": {"var": "x", "note-size": true}}}`,
+			result: "var x = \"hi\" // 2 bytes",
+		},
+		{
+			name:   "flags reset",
+			req:    `{"jsonrpc": "2.0", "id": 2, "method": "quote", "params": {"mode": "q", "input": "hi"}}`,
+			result: `"hi"`,
+		},
+		{
+			name: "invalid mode",
+			req:  `{"jsonrpc": "2.0", "id": 3, "method": "quote", "params": {"mode": "nope", "input": "hi"}}`,
+			code: rpcInvalidParams,
+		},
+		{
+			name: "input file",
+			req:  `{"jsonrpc": "2.0", "id": 4, "method": "quote", "params": {"mode": "q", "flags": {"f": "/etc/passwd"}}}`,
+			code: rpcInvalidParams,
+		},
+		{
+			name: "separator",
+			req:  `{"jsonrpc": "2.0", "id": 5, "method": "quote", "params": {"mode": "q", "flags": {"s": ","}}}`,
+			code: rpcInvalidParams,
+		},
+		{
+			name: "unknown flag",
+			req:  `{"jsonrpc": "2.0", "id": 6, "method": "quote", "params": {"mode": "q", "flags": {"nope": 1}}}`,
+			code: rpcInvalidParams,
+		},
+		{
+			name: "unknown method",
+			req:  `{"jsonrpc": "2.0", "id": 7, "method": "unquote"}`,
+			code: rpcMethodNotFound,
+		},
+		{
+			name: "version",
+			req:  `{"id": 8, "method": "quote", "params": {"mode": "q"}}`,
+			code: rpcInvalidRequest,
+		},
+	}
+	for _, c := range cases {
+		resp := s.handle(json.RawMessage(c.req))
+		switch {
+		case resp == nil:
+			t.Errorf("%s: no response", c.name)
+		case c.code != 0:
+			if resp.Error == nil || resp.Error.Code != c.code {
+				t.Errorf("%s: response %+v; want error %d", c.name, resp, c.code)
+			}
+		case resp.Error != nil:
+			t.Errorf("%s: error %d: %s", c.name, resp.Error.Code, resp.Error.Message)
+		case *resp.Result != c.result:
+			t.Errorf("%s: result = %s; want %s", c.name, *resp.Result, c.result)
+		}
+	}
+
+	if len(inputFiles) != 0 || sep != "\n" {
+		t.Errorf("-f = %q and -s = %q; want them unset by requests", inputFiles, sep)
+	}
+
+	const note = `{"jsonrpc": "2.0", "method": "quote", "params": {"mode": "q", "input": "hi", "flags": {"var": "x"}}}`
+	if resp := s.handle(json.RawMessage(note)); resp != nil {
+		t.Errorf("notification: response %+v; want none", resp)
+	}
+	if varName != "" {
+		t.Errorf("-var = %q after notification; want it reset", varName)
+	}
+
+	const bad = `{"jsonrpc": "2.0", "id": 9, "method": "quote", "params": {"mode": "nope"}}`
+	if resp := s.handle(json.RawMessage(bad)); resp == nil || resp.Error == nil || !strings.Contains(resp.Error.Message, "nope") {
+		t.Errorf("invalid mode: response %+v; want an error naming the mode", resp)
+	}
+}
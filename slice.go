@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Slice flags.
+var (
+	// uniqueElems controls whether duplicate elements are removed from
+	// string slices, keeping the first of each.
+	uniqueElems = false
+	// sortElems controls whether the elements of string slices are sorted.
+	sortElems = false
+	// shuffleElems controls whether the elements of slice modes are
+	// shuffled, seeded by shuffleSeed.
+	shuffleElems = false
+	shuffleSeed  = uint64(0)
+)
+
+// elems holds every input when a mode writes multiple inputs as a single
+// expression (see collectElems). Otherwise, it is nil.
+var elems [][]byte
+
+// collectsElems returns whether mode writes multiple inputs as a single
+// expression.
+func collectsElems(mode string) bool {
+	return mode == "sorted" || mode == "fnv" || mode == "jumptable"
+}
+
+// collectElems returns inputs as a single input if mode writes multiple inputs
+// as a single expression, setting elems to inputs. The single input is the
+// concatenation of inputs. Otherwise, inputs is returned unchanged.
+func collectElems(inputs [][]byte, mode string) [][]byte {
+	if len(inputs) < 2 || !collectsElems(mode) {
+		return inputs
+	}
+	elems = inputs
+	return [][]byte{bytes.Join(inputs, nil)}
+}
+
+// shuffle shuffles the n elements swapped by swap into an order determined only
+// by -seed. Unlike math/rand, whose default source and shuffle may change
+// between Go releases, it uses its own generator (SplitMix64) and a
+// Fisher-Yates shuffle, so the same seed always gives the same order on every
+// platform and release of goquote.
+func shuffle(n int, swap func(i, j int)) {
+	state := shuffleSeed
+	next := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		return z ^ z>>31
+	}
+	for i := n - 1; i > 0; i-- {
+		// The modulo bias is negligible for any slice that fits in
+		// memory, and is part of the fixed order anyway.
+		j := int(next() % uint64(i+1))
+		swap(i, j)
+	}
+}
+
+// writeStringSlice writes elems as a []string of quoted strings.
+func writeStringSlice(buf *bytes.Buffer, elems []string) {
+	buf.WriteString("[]string{")
+	for i, e := range elems {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strconv.Quote(e))
+	}
+	buf.WriteByte('}')
+}
+
+// writeSorted writes b as a []byte with its bytes in ascending order or, if
+// there are multiple inputs, elems as a []string in lexical order. The order is
+// the same as that of slices.Sort and bytes.Compare, so the result is suitable
+// for binary searches.
+func writeSorted(buf *bytes.Buffer, b []byte, depth int) {
+	if elems == nil {
+		p := append([]byte(nil), b...)
+		sort.Slice(p, func(i, j int) bool { return p[i] < p[j] })
+		write(buf, p, "b", depth)
+		return
+	}
+
+	strs := make([]string, len(elems))
+	for i, e := range elems {
+		strs[i] = string(e)
+	}
+	sort.Strings(strs)
+	writeStringSlice(buf, strs)
+}
+
+// writeWords writes the whitespace-separated words of b as a []string,
+// deduplicated by -unique and sorted by -sort or shuffled by -shuffle.
+func writeWords(buf *bytes.Buffer, b []byte) {
+	words := strings.Fields(string(b))
+	if uniqueElems {
+		seen := make(map[string]bool, len(words))
+		unique := words[:0]
+		for _, w := range words {
+			if !seen[w] {
+				seen[w] = true
+				unique = append(unique, w)
+			}
+		}
+		words = unique
+	}
+	if sortElems {
+		sort.Strings(words)
+	}
+	if shuffleElems {
+		shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+	}
+	writeStringSlice(buf, words)
+}
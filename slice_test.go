@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestWords2Slice(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"a b c", `[]string{"a", "b", "c"}`},
+		{"a  \t\n b", `[]string{"a", "b"}`},
+		{"  a b", `[]string{"a", "b"}`},
+		{"a b \n\t", `[]string{"a", "b"}`},
+		{" \t\n", `[]string{}`},
+	}
+	for _, c := range cases {
+		if got := writeString([]byte(c.in), "words2slice"); got != c.want {
+			t.Errorf("words2slice of %q = %s; want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestWords2SliceUniqueSort(t *testing.T) {
+	defer func(unique, sorted bool) { uniqueElems, sortElems = unique, sorted }(uniqueElems, sortElems)
+	uniqueElems, sortElems = true, true
+
+	const want = `[]string{"a", "b", "c"}`
+	if got := writeString([]byte(" c a  b a c "), "words2slice"); got != want {
+		t.Errorf("words2slice with -unique -sort = %s; want %s", got, want)
+	}
+}
+
+func TestSorted(t *testing.T) {
+	cases := []struct {
+		inputs []string
+		want   string
+	}{
+		{[]string{"cab"}, "[]byte{0x61, 0x62, 0x63} // sorted"},
+		{[]string{"abc"}, "[]byte{0x61, 0x62, 0x63} // sorted"},
+		{[]string{"baab"}, "[]byte{0x61, 0x61, 0x62, 0x62} // sorted"},
+		{[]string{"b", "a", "c"}, `[]string{"a", "b", "c"} // sorted`},
+		{[]string{"a", "b", "c"}, `[]string{"a", "b", "c"} // sorted`},
+		{[]string{"b", "a", "b"}, `[]string{"a", "b", "b"} // sorted`},
+	}
+	for _, c := range cases {
+		if got := renderString("sorted", c.inputs...); got != c.want {
+			t.Errorf("sorted mode of %q = %s; want %s", c.inputs, got, c.want)
+		}
+	}
+}
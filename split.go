@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Split file flags.
+var (
+	// splitSize, if positive, is the maximum number of bytes of the input
+	// written to each file of -o-prefix, instead of writing the output to
+	// standard output.
+	splitSize = 0
+	// outPrefix is the path prefix of the files written by -split-files.
+	outPrefix = ""
+)
+
+// splitModes are the modes supported by -split-files: those writing a []byte
+// of exactly the bytes of their input, so that chunks can be concatenated.
+var splitModes = map[string]bool{"b": true, "0b": true, "bs": true, "bsa": true}
+
+// writeSplitFiles writes b in chunks of at most -split-files bytes to the Go
+// source files PREFIX0.go, PREFIX1.go, and so on, each declaring one chunk as
+// a variable, and writes PREFIX.go declaring the -var as their concatenation.
+// Each file begins with the -header and -package clause, and is formatted by
+// -fmt-cmd, if set.
+func writeSplitFiles(b []byte, mode string) error {
+	if !splitModes[mode] {
+		fatalf("-split-files is not supported by mode %q: must be one of b, 0b, bs, or bsa", mode)
+	}
+
+	r, size := utf8.DecodeRuneInString(varName)
+	name := string(unicode.ToLower(r)) + varName[size:] + "Chunk"
+	total := len(b)
+	var chunks []string
+	for len(chunks) == 0 || len(b) > 0 {
+		n := splitSize
+		if n > len(b) {
+			n = len(b)
+		}
+		chunk := name + strconv.Itoa(len(chunks))
+		var buf bytes.Buffer
+		writeFileHeader(&buf)
+		buf.WriteString("var " + chunk + " = ")
+		write(&buf, b[:n], mode, 0)
+		buf.WriteByte('\n')
+		if err := writeSplitFile(outPrefix+strconv.Itoa(len(chunks))+".go", buf.Bytes()); err != nil {
+			return err
+		}
+		chunks = append(chunks, chunk)
+		b = b[n:]
+	}
+
+	var buf bytes.Buffer
+	writeFileHeader(&buf)
+	writeNolint(&buf, 0)
+	buf.WriteString("var " + varName + " = func() []byte {")
+	newline(&buf, 1)
+	buf.WriteString("b := make([]byte, 0, " + strconv.Itoa(total) + ")")
+	for _, chunk := range chunks {
+		newline(&buf, 1)
+		buf.WriteString("b = append(b, " + chunk + "...)")
+	}
+	newline(&buf, 1)
+	buf.WriteString("return b\n}()\n")
+	return writeSplitFile(outPrefix+".go", buf.Bytes())
+}
+
+// writeSplitFile writes the source p to path, formatted by -fmt-cmd if set.
+func writeSplitFile(path string, p []byte) error {
+	if fmtCmd != "" {
+		p = runFmtCmd(p)
+	}
+	if !keepTrailingWS {
+		p = trimTrailingSpace(p)
+	}
+	return ioutil.WriteFile(path, p, 0644)
+}
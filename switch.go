@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// switchCases controls whether the inputs are written as the cases of a
+// switch statement over their indices, each returning its input.
+var switchCases = false
+
+// writeSwitch writes a switch statement over the variable i in which each case,
+// labeled with the index of an input, returns that input written in the given
+// mode. It is meant to be pasted into a function mapping indices to values.
+func writeSwitch(buf *bytes.Buffer, inputs [][]byte, mode string, depth int) {
+	buf.WriteString("switch i {")
+	for i, b := range inputs {
+		newline(buf, depth)
+		buf.WriteString("case " + strconv.Itoa(i) + ":")
+		newline(buf, depth+1)
+		buf.WriteString("return ")
+		if comment := writeExpr(buf, b, mode, depth+1); comment != "" {
+			buf.WriteString(lineComment(comment))
+		}
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// tableChar returns the Char column of the table mode for the byte c. Printable
+// ASCII is written as-is, space as SP, control bytes in caret notation, and
+// all other bytes as a period.
+func tableChar(c byte) string {
+	switch {
+	case c == ' ':
+		return "SP"
+	case c > ' ' && c < 0x7f:
+		return string(c)
+	case c < ' ' || c == 0x7f:
+		return caretNotation(c)
+	}
+	return "."
+}
+
+// writeTable writes b as a []byte literal that opens with a block comment
+// containing a table of each byte's offset, decimal, hexadecimal, binary, and
+// character values. The comment is written inside the literal's braces so that
+// the table remains a single expression wherever it is written (a multi-line
+// comment before a return value would end the return statement).
+func writeTable(buf *bytes.Buffer, b []byte, depth int) {
+	if len(b) == 0 {
+		buf.WriteString("[]byte{}")
+		return
+	}
+
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "Offset\tDec\tHex\tBin\tChar\n")
+	for i, c := range b {
+		fmt.Fprintf(tw, "%d\t%d\t0x%02x\t%08b\t%s\n", i, c, c, c, tableChar(c))
+	}
+	tw.Flush()
+
+	buf.WriteString("[]byte{")
+	newline(buf, depth+1)
+	buf.WriteString("/*")
+	// The rows are indented a level deeper than the comment delimiters, as
+	// gofmt indents the lines of block comments.
+	for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		newline(buf, depth+2)
+		buf.WriteString(line)
+	}
+	newline(buf, depth+1)
+	buf.WriteString("*/")
+	newline(buf, depth+1)
+	for i, c := range b {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(buf, "0x%02x,", c)
+	}
+	newline(buf, depth)
+	buf.WriteByte('}')
+}
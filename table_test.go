@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	const want = "[]byte{\n" +
+		"\t/*\n" +
+		"\t\tOffset  Dec  Hex   Bin       Char\n" +
+		"\t\t0       0    0x00  00000000  ^@\n" +
+		"\t\t1       32   0x20  00100000  SP\n" +
+		"\t\t2       65   0x41  01000001  A\n" +
+		"\t\t3       127  0x7f  01111111  ^?\n" +
+		"\t\t4       128  0x80  10000000  .\n" +
+		"\t\t5       255  0xff  11111111  .\n" +
+		"\t*/\n" +
+		"\t0x00, 0x20, 0x41, 0x7f, 0x80, 0xff,\n" +
+		"}"
+
+	var buf bytes.Buffer
+	writeTable(&buf, []byte{0x00, 0x20, 0x41, 0x7f, 0x80, 0xff}, 0)
+	if got := buf.String(); got != want {
+		t.Errorf("writeTable() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteTableAllBytes(t *testing.T) {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	var buf bytes.Buffer
+	writeTable(&buf, b, 0)
+
+	// Every byte must have a row of its own with all five columns, so that
+	// no character value breaks the table's layout.
+	lines := strings.Split(buf.String(), "\n")
+	rows := lines[3 : 3+len(b)]
+	for i, row := range rows {
+		if fields := strings.Fields(row); len(fields) != 5 {
+			t.Errorf("row %d = %q: has %d columns, want 5", i, row, len(fields))
+		}
+	}
+	if lines[3+len(b)] != "\t*/" {
+		t.Errorf("line after rows = %q; want end of comment", lines[3+len(b)])
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+// keepTrailingWS controls whether trailing whitespace is kept on the lines of
+// the output instead of being removed.
+var keepTrailingWS = false
+
+// trimTrailingSpace returns p with the spaces and tabs that end each of its
+// lines removed. Since p is Go source, whitespace inside a raw string literal
+// is part of its value and is kept. p is scanned just enough to tell where raw
+// strings begin and end: a backquote in an interpreted string, rune literal,
+// or comment does not begin one.
+func trimTrailingSpace(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	// state is the kind of token p[i] is in: 0 (code), '"' (interpreted
+	// string), '\'' (rune literal), '`' (raw string), '/' (line comment),
+	// or '*' (block comment).
+	state := byte(0)
+	// star is whether the previous byte of a block comment is a '*'.
+	star := false
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch state {
+		case 0:
+			switch {
+			case c == '"' || c == '\'' || c == '`':
+				state = c
+			case c == '/' && i+1 < len(p) && (p[i+1] == '/' || p[i+1] == '*'):
+				state, star = p[i+1], false
+				out = append(out, c)
+				i++
+				c = p[i]
+			}
+		case '"', '\'':
+			if c == '\\' && i+1 < len(p) {
+				out = append(out, c)
+				i++
+				c = p[i]
+			} else if c == state || c == '\n' {
+				state = 0
+			}
+		case '`':
+			if c == '`' {
+				state = 0
+			}
+		case '/':
+			if c == '\n' {
+				state = 0
+			}
+		case '*':
+			if c == '/' && star {
+				state = 0
+			}
+			star = c == '*'
+		}
+
+		if c == '\n' && state != '`' {
+			for n := len(out); n > 0 && (out[n-1] == ' ' || out[n-1] == '\t'); n-- {
+				out = out[:n-1]
+			}
+		}
+		out = append(out, c)
+	}
+	if state != '`' {
+		for n := len(out); n > 0 && (out[n-1] == ' ' || out[n-1] == '\t'); n-- {
+			out = out[:n-1]
+		}
+	}
+	return out
+}
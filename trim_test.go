@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestTrimTrailingSpace(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{
+			"wrapped slice",
+			"[]byte{ \n\t0x61, 0x62, \t\n\t0x63,  \n}  ",
+			"[]byte{\n\t0x61, 0x62,\n\t0x63,\n}",
+		},
+		{
+			"comment block",
+			"/* \n\tOffset  Dec  \n\t*/ \n[]byte{0x61} // \"a\"  \n",
+			"/*\n\tOffset  Dec\n\t*/\n[]byte{0x61} // \"a\"\n",
+		},
+		{
+			"raw string",
+			"x := `a  \n\tb \t\n` \ny := 1 \n",
+			"x := `a  \n\tb \t\n`\ny := 1\n",
+		},
+		{
+			"backquote in string",
+			"\"`\"  \nx := `a `  \n",
+			"\"`\"\nx := `a `\n",
+		},
+		{
+			"backquote in comment",
+			"// `a  \n/* ` */ \n'`' \n",
+			"// `a\n/* ` */\n'`'\n",
+		},
+	}
+	for _, c := range cases {
+		if got := string(trimTrailingSpace([]byte(c.in))); got != c.want {
+			t.Errorf("%s: trimTrailingSpace(%q) = %q; want %q", c.name, c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tsvTypes are the Go types that may be given to tsv columns.
+var tsvTypes = map[string]bool{
+	"string":  true,
+	"int":     true,
+	"bool":    true,
+	"float64": true,
+}
+
+// floatFmt, if set, is the fmt verb used to write floating-point values, such
+// as %.2f. Otherwise, floats are written in the shortest form that represents
+// them exactly.
+var floatFmt = ""
+
+// floatVerb matches the fmt verbs allowed by -float-fmt: those that write a
+// float64 as a Go floating-point literal.
+var floatVerb = regexp.MustCompile(`^%[+#]?(\.[0-9]+)?[eEfFgGxX]$`)
+
+// formatFloat returns f as a Go floating-point literal, formatted by -float-fmt
+// if set.
+func formatFloat(f float64) string {
+	if floatFmt != "" {
+		return fmt.Sprintf(floatFmt, f)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// tsvValue returns v, a value of a tsv column of type typ, as a Go expression
+// of that type.
+func tsvValue(v, typ string) (string, error) {
+	switch typ {
+	case "int":
+		n, err := strconv.ParseInt(v, 0, 64)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "bool":
+		t, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(t), nil
+	case "float64":
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "", err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return "", &strconv.NumError{Func: "ParseFloat", Num: v, Err: strconv.ErrRange}
+		}
+		return formatFloat(f), nil
+	}
+	return strconv.Quote(v), nil
+}
+
+// writeTSV writes the tab-separated rows of b as a slice of anonymous structs.
+// The first row is a header of NAME[:TYPE] columns giving each field's name and
+// type, where TYPE is one of string (the default), int, bool, or float64. Each
+// value is parsed as its column's type and written as a Go literal of that
+// type. Blank lines after the header are skipped. Values that cannot be
+// parsed, and rows with the wrong number of columns, are reported by row and
+// column.
+func writeTSV(buf *bytes.Buffer, b []byte, depth int) {
+	lines := splitLines(b)
+	if len(lines) == 0 || lines[0] == "" {
+		fatalf("tsv: missing header row")
+	}
+
+	header := strings.Split(lines[0], "\t")
+	types := make([]string, len(header))
+	for i, col := range header {
+		types[i] = "string"
+		if colon := strings.LastIndexByte(col, ':'); colon != -1 {
+			header[i], types[i] = col[:colon], col[colon+1:]
+		}
+		if !tsvTypes[types[i]] {
+			fatalf("tsv: column %d (%s): invalid type %q: must be one of string, int, bool, or float64", i+1, header[i], types[i])
+		}
+	}
+	names := fieldNames(header)
+
+	var rows [][]string
+	for i, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		row := strings.Split(line, "\t")
+		if len(row) != len(header) {
+			fatalf("tsv: row %d: has %d columns, expected %d", i+2, len(row), len(header))
+		}
+		for j, v := range row {
+			expr, err := tsvValue(v, types[j])
+			if err != nil {
+				fatalf("tsv: row %d, column %d (%s): invalid %s %q", i+2, j+1, header[j], types[j], v)
+			}
+			row[j] = expr
+		}
+		rows = append(rows, row)
+	}
+	if shuffleElems {
+		shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+	}
+	writeStructSlice(buf, names, types, rows, depth)
+}